@@ -0,0 +1,110 @@
+// Command gazetteer generates the embedded list of places used to name locations from a GeoNames
+// dump, see pkg/locations/data/README.md.
+package main
+
+import (
+	"archive/zip"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/AustinBayley/activity_tracker_api/pkg/locations"
+	"github.com/rs/zerolog/log"
+)
+
+func main() {
+	in := flag.String("in", "cities15000.zip", "GeoNames dump to read, either the .txt file or its .zip archive")
+	countryInfo := flag.String("countries", "countryInfo.txt", "GeoNames country information to name countries from")
+	out := flag.String("out", "pkg/locations/data/places.tsv", "file to write the places to")
+	minPopulation := flag.Int64("min-population", 15000, "smallest population of a place to keep")
+	flag.Parse()
+
+	cf, err := os.Open(*countryInfo)
+	if err != nil {
+		log.Fatal().
+			Err(err).
+			Str("countries", *countryInfo).
+			Msg("failed to open geonames country information")
+	}
+	defer cf.Close()
+
+	countries, err := locations.ReadCountryNames(cf)
+	if err != nil {
+		log.Fatal().
+			Err(err).
+			Str("countries", *countryInfo).
+			Msg("failed to read geonames country information")
+	}
+
+	r, closer, err := open(*in)
+	if err != nil {
+		log.Fatal().
+			Err(err).
+			Str("in", *in).
+			Msg("failed to open geonames dump")
+	}
+	defer closer.Close()
+
+	ps, err := locations.ReadGeoNames(r, *minPopulation, countries)
+	if err != nil {
+		log.Fatal().
+			Err(err).
+			Str("in", *in).
+			Msg("failed to read geonames dump")
+	}
+
+	f, err := os.Create(*out)
+	if err != nil {
+		log.Fatal().
+			Err(err).
+			Str("out", *out).
+			Msg("failed to create places file")
+	}
+	defer f.Close()
+
+	comments := []string{
+		fmt.Sprintf("Generated from the GeoNames %s dump by cmd/gazetteer, keeping places with a population of at least %d.", filepath.Base(*in), *minPopulation),
+		"Data from GeoNames (https://www.geonames.org), licensed under CC BY 4.0. See README.md.",
+	}
+	if err := locations.WriteGazetteer(f, ps, comments...); err != nil {
+		log.Fatal().
+			Err(err).
+			Str("out", *out).
+			Msg("failed to write places file")
+	}
+
+	log.Info().
+		Int("places", len(ps)).
+		Str("out", *out).
+		Msg("generated gazetteer")
+}
+
+// open opens a GeoNames dump, reading the text file from within it if it is a zip archive.
+func open(name string) (io.Reader, io.Closer, error) {
+	if !strings.EqualFold(filepath.Ext(name), ".zip") {
+		f, err := os.Open(name)
+		return f, f, err
+	}
+
+	archive, err := zip.OpenReader(name)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, file := range archive.File {
+		if strings.EqualFold(filepath.Ext(file.Name), ".txt") {
+			r, err := file.Open()
+			if err != nil {
+				archive.Close()
+				return nil, nil, err
+			}
+			return r, archive, nil
+		}
+	}
+
+	archive.Close()
+	return nil, nil, fmt.Errorf("no .txt file in %s", name)
+}
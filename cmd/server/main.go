@@ -6,6 +6,9 @@ import (
 	"github.com/AustinBayley/activity_tracker_api/pkg/activities"
 	"github.com/AustinBayley/activity_tracker_api/pkg/api"
 	"github.com/AustinBayley/activity_tracker_api/pkg/challenges"
+	"github.com/AustinBayley/activity_tracker_api/pkg/progress"
+	"github.com/AustinBayley/activity_tracker_api/pkg/routes"
+	"github.com/AustinBayley/activity_tracker_api/pkg/tracks"
 	"github.com/AustinBayley/activity_tracker_api/pkg/users"
 	"github.com/kelseyhightower/envconfig"
 	"github.com/rs/zerolog/log"
@@ -39,9 +42,12 @@ func main() {
 	db := client.Database(cfg.DatabaseName)
 
 	acts := activities.New(db.Collection("activities"))
+	trs := tracks.New(db.Collection("tracks"))
 	cds := challenges.NewDetails(db.Collection("challenges"))
 	ms := challenges.NewMemberships(db.Collection("memberships"))
-	cs := challenges.New(cds, ms)
+	ts := challenges.NewTeams(db.Collection("teams"))
+	cs := challenges.New(cds, ms, ts)
+	rs := routes.New(db.Collection("routes"))
 
 	uds := users.NewDetails(db.Collection("users"))
 	us := users.New(
@@ -49,6 +55,14 @@ func main() {
 		ms,
 		cs,
 		acts,
+		trs,
+	)
+
+	ps := progress.New(
+		progress.NewRecords(db.Collection("progress")),
+		acts,
+		cs,
+		rs,
 	)
 
 	ctx := context.Background()
@@ -64,12 +78,30 @@ func main() {
 			Msg("failed to setup activities service")
 	}
 
+	if err := trs.Setup(ctx); err != nil {
+		log.Fatal().
+			Err(err).
+			Msg("failed to setup tracks service")
+	}
+
 	if err := us.Setup(ctx); err != nil {
 		log.Fatal().
 			Err(err).
 			Msg("failed to setup users service")
 	}
 
+	if err := rs.Setup(ctx); err != nil {
+		log.Fatal().
+			Err(err).
+			Msg("failed to setup routes service")
+	}
+
+	if err := ps.Setup(ctx); err != nil {
+		log.Fatal().
+			Err(err).
+			Msg("failed to setup progress service")
+	}
+
 	err = api.NewAPI(api.NewConfig(
 		cfg.Environment,
 		db,
@@ -78,6 +110,9 @@ func main() {
 		acts,
 		cs,
 		us,
+		ps,
+		rs,
+		trs,
 	)).Start()
 
 	if err != nil {
@@ -8,6 +8,7 @@ require (
 	github.com/gin-gonic/gin v1.11.0
 	github.com/go-playground/validator/v10 v10.27.0
 	github.com/kelseyhightower/envconfig v1.4.0
+	github.com/muktihari/fit v0.24.0
 	github.com/rs/zerolog v1.34.0
 	github.com/uber/h3-go/v4 v4.3.0
 	go.mongodb.org/mongo-driver/v2 v2.3.0
@@ -116,6 +116,9 @@ type ListOptions struct {
 	Skip  int64
 
 	User *service.ID
+	// From and To limit activities to those starting within the given times (inclusive).
+	From *time.Time
+	To   *time.Time
 }
 
 func NewListOptions() *ListOptions {
@@ -137,6 +140,16 @@ func (opts *ListOptions) SetUser(id service.ID) *ListOptions {
 	return opts
 }
 
+func (opts *ListOptions) SetFrom(from time.Time) *ListOptions {
+	opts.From = &from
+	return opts
+}
+
+func (opts *ListOptions) SetTo(to time.Time) *ListOptions {
+	opts.To = &to
+	return opts
+}
+
 // List retrieves activities based on the given criteria.
 func (svc *Service) List(ctx context.Context, opts ListOptions, activities interface{}) error {
 	options := options.Find()
@@ -154,6 +167,17 @@ func (svc *Service) List(ctx context.Context, opts ListOptions, activities inter
 		filter = append(filter, bson.E{Key: "userID", Value: opts.User.ConvertID()})
 	}
 
+	if opts.From != nil || opts.To != nil {
+		start := bson.D{}
+		if opts.From != nil {
+			start = append(start, bson.E{Key: "$gte", Value: *opts.From})
+		}
+		if opts.To != nil {
+			start = append(start, bson.E{Key: "$lte", Value: *opts.To})
+		}
+		filter = append(filter, bson.E{Key: "start", Value: start})
+	}
+
 	cursor, err := svc.Find(ctx, filter, options)
 	if err != nil {
 		return fmt.Errorf("%w: %w", ErrUnknown, err)
@@ -7,6 +7,7 @@ import (
 
 	"github.com/AustinBayley/activity_tracker_api/pkg/activities"
 	"github.com/AustinBayley/activity_tracker_api/pkg/service"
+	"github.com/AustinBayley/activity_tracker_api/pkg/tracks"
 	jsonpatch "github.com/evanphx/json-patch/v5"
 	"github.com/gin-gonic/gin"
 	"github.com/rs/zerolog/log"
@@ -111,6 +112,8 @@ func (a *API) PostUserActivity(req *gin.Context) {
 	}
 	activity.ID = oid
 
+	a.refreshUserProgress(req, userID)
+
 	req.JSON(http.StatusCreated, activity)
 }
 
@@ -228,6 +231,29 @@ func (a *API) PatchActivity(req *gin.Context) {
 		return
 	}
 
+	// A recorded track is timestamped and belongs to the user, so the activity's times and user
+	// can't be changed while it has one. Its value can be corrected, as with PutActivityTrack.
+	if !activity.Start.Equal(stored.Start) || !activity.End.Equal(stored.End) || activity.UserID != stored.UserID {
+		err := a.tracks.Get(req, aID, &tracks.Track{})
+		switch {
+		case err == nil:
+			req.JSON(http.StatusConflict, ErrorResponse{
+				Cause: "start, end and user of an activity with a track can't be changed",
+			})
+			return
+		case !errors.Is(err, tracks.ErrNotFound):
+			log.Error().
+				Err(err).
+				Str("activityID", id).
+				Msg("error getting activity track")
+
+			req.JSON(http.StatusInternalServerError, ErrorResponse{
+				Cause: InternalServer,
+			})
+			return
+		}
+	}
+
 	// Update activity
 	if err = a.activities.Update(req, activity); err != nil {
 		log.Error().
@@ -254,6 +280,11 @@ func (a *API) PatchActivity(req *gin.Context) {
 		return
 	}
 
+	a.refreshUserProgress(req, activity.UserID)
+	if stored.UserID != activity.UserID {
+		a.refreshUserProgress(req, stored.UserID)
+	}
+
 	req.JSON(http.StatusNoContent, nil)
 }
 
@@ -310,6 +341,23 @@ func (a *API) DeleteActivity(req *gin.Context) {
 		return
 	}
 
+	// The track goes first, so it isn't left behind if deleting the activity fails
+	trackOpts := tracks.TrackDeleteOpts{
+		Activity: &aID,
+	}
+
+	if err := a.tracks.Delete(req, trackOpts); err != nil {
+		log.Error().
+			Err(err).
+			Str("activityID", id).
+			Msg("error deleting activity track")
+
+		req.JSON(http.StatusInternalServerError, ErrorResponse{
+			Cause: InternalServer,
+		})
+		return
+	}
+
 	opts := activities.ActivityDeleteOpts{
 		ID: &aID,
 	}
@@ -333,6 +381,8 @@ func (a *API) DeleteActivity(req *gin.Context) {
 		return
 	}
 
+	a.refreshUserProgress(req, activity.UserID)
+
 	req.JSON(http.StatusNoContent, nil)
 }
 
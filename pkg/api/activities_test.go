@@ -10,7 +10,9 @@ import (
 
 	"github.com/AustinBayley/activity_tracker_api/pkg/activities"
 	"github.com/AustinBayley/activity_tracker_api/pkg/api"
+	"github.com/AustinBayley/activity_tracker_api/pkg/locations"
 	"github.com/AustinBayley/activity_tracker_api/pkg/service"
+	"github.com/AustinBayley/activity_tracker_api/pkg/tracks"
 	"github.com/gin-gonic/gin"
 )
 
@@ -121,6 +123,67 @@ func TestUpdateActivity(t *testing.T) {
 	}
 }
 
+func TestUpdateTrackedActivity(t *testing.T) {
+	activity, cleanup, _ := CreateTestActivity(context.Background(), "Update Tracked Activity")
+	t.Cleanup(cleanup)
+
+	track := tracks.Track{
+		Activity: activity.ID,
+		User:     activity.UserID,
+		Points: tracks.Points{
+			{Time: activity.Start, LatLng: locations.LatLng{Lat: 51.45, Lng: -2.60}},
+			{Time: activity.End, LatLng: locations.LatLng{Lat: 51.45, Lng: -2.58}},
+		},
+	}
+	if _, err := Tracks.Create(context.Background(), &track); err != nil {
+		t.Fatalf("failed to create test track: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = Tracks.Delete(context.Background(), tracks.TrackDeleteOpts{Activity: &activity.ID})
+	})
+
+	// The type and distance can be changed, but not the times the track was recorded at
+	for _, tt := range []struct {
+		patch  string
+		status int
+	}{
+		{`[{"op":"replace","path":"/type","value":"walking"}]`, 204},
+		{`[{"op":"replace","path":"/value","value":20}]`, 204},
+		{`[{"op":"replace","path":"/start","value":"2020-01-01T00:00:00Z"}]`, 409},
+	} {
+		req := httptest.NewRequest("PATCH", "/activities/"+string(activity.ID), strings.NewReader(tt.patch))
+		req.Header.Set("Content-Type", "application/json-patch+json")
+		recorder := httptest.NewRecorder()
+		ctx := gin.CreateTestContextOnly(recorder, API.Engine)
+		ctx.AddParam("activityID", string(activity.ID))
+		ctx.Request = req
+
+		ctx.Set(api.UserCtxKey, api.RequestContext{
+			UserID: service.ID("test_user"),
+		})
+
+		API.PatchActivity(ctx)
+
+		if ctx.Writer.Status() != tt.status {
+			t.Fatalf("expected status %d after %s, got %d", tt.status, tt.patch, ctx.Writer.Status())
+		}
+
+		if err := Tracks.Get(ctx, activity.ID, &tracks.Track{}); err != nil {
+			t.Errorf("expected track to be kept after %s, got %v", tt.patch, err)
+		}
+	}
+
+	updated := activities.Activity{}
+	if err := Activities.Get(context.Background(), activity.ID, &updated); err != nil {
+		t.Fatalf("failed to get updated activity: %v", err)
+	}
+
+	// Times are stored to the millisecond
+	if updated.Value != 20 || updated.Start.Sub(activity.Start).Abs() >= time.Millisecond {
+		t.Errorf("expected value 20 and start %v, got %f and %v", activity.Start, updated.Value, updated.Start)
+	}
+}
+
 func TestDeleteActivity(t *testing.T) {
 	activity, cleanup, _ := CreateTestActivity(context.Background(), "Delete Activity")
 	t.Cleanup(cleanup)
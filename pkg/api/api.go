@@ -11,6 +11,9 @@ import (
 
 	"github.com/AustinBayley/activity_tracker_api/pkg/activities"
 	"github.com/AustinBayley/activity_tracker_api/pkg/challenges"
+	"github.com/AustinBayley/activity_tracker_api/pkg/progress"
+	"github.com/AustinBayley/activity_tracker_api/pkg/routes"
+	"github.com/AustinBayley/activity_tracker_api/pkg/tracks"
 	"github.com/AustinBayley/activity_tracker_api/pkg/users"
 	"github.com/gin-gonic/gin"
 	"github.com/rs/zerolog/log"
@@ -40,6 +43,9 @@ type Config struct {
 	activities *activities.Service
 	challenges *challenges.Service
 	users      *users.Service
+	progress   *progress.Service
+	routes     *routes.Service
+	tracks     *tracks.Service
 }
 
 func NewConfig(
@@ -51,6 +57,9 @@ func NewConfig(
 	activities *activities.Service,
 	challenges *challenges.Service,
 	users *users.Service,
+	progress *progress.Service,
+	routes *routes.Service,
+	tracks *tracks.Service,
 ) Config {
 	return Config{
 		Environment: environment,
@@ -60,6 +69,9 @@ func NewConfig(
 		activities:  activities,
 		challenges:  challenges,
 		users:       users,
+		progress:    progress,
+		routes:      routes,
+		tracks:      tracks,
 	}
 }
 
@@ -72,6 +84,9 @@ type API struct {
 	users      *users.Service
 	challenges *challenges.Service
 	activities *activities.Service
+	progress   *progress.Service
+	routes     *routes.Service
+	tracks     *tracks.Service
 }
 
 func NewAPI(cfg Config) *API {
@@ -84,6 +99,9 @@ func NewAPI(cfg Config) *API {
 		cfg.users,
 		cfg.challenges,
 		cfg.activities,
+		cfg.progress,
+		cfg.routes,
+		cfg.tracks,
 	}
 }
 
@@ -100,17 +118,47 @@ func (a *API) Start() error {
 	a.GET("/health", a.HealthCheck)
 
 	// Activity routes
-	a.GET("/activities/:activityID", a.GetActivity)       // public
-	a.PATCH("/activities/:activityID", a.PatchActivity)   // valid user
-	a.DELETE("/activities/:activityID", a.DeleteActivity) // valid user
+	a.GET("/activities/:activityID", a.GetActivity)            // public
+	a.PATCH("/activities/:activityID", a.PatchActivity)        // valid user
+	a.DELETE("/activities/:activityID", a.DeleteActivity)      // valid user
+	a.GET("/activities/:activityID/track", a.GetActivityTrack) // valid user
+	a.PUT("/activities/:activityID/track", a.PutActivityTrack) // valid user
 
 	// Challenge Routes
-	a.GET("/challenges", a.GetChallenges)                            // public
-	a.POST("/challenges", a.PostChallenge)                           // auth
-	a.GET("/challenges/:id", a.GetChallenge)                         // public
-	a.DELETE("/challenges/:id", a.DeleteChallenge)                   // auth
-	a.PATCH("/challenges/:id", a.PatchChallenge)                     // auth
-	a.GET("/challenges/:id/members/:userID/progress", a.GetProgress) // public
+	a.GET("/challenges", a.GetChallenges)                                                 // public
+	a.GET("/challenges/nearby", a.GetNearbyChallenges)                                    // public
+	a.POST("/challenges", a.PostChallenge)                                                // auth
+	a.GET("/challenges/:id", a.GetChallenge)                                              // public
+	a.DELETE("/challenges/:id", a.DeleteChallenge)                                        // auth
+	a.PATCH("/challenges/:id", a.PatchChallenge)                                          // auth
+	a.PUT("/challenges/:id/route", a.PutChallengeRoute)                                   // auth
+	a.GET("/challenges/:id/route.geojson", a.GetChallengeRouteGeoJSON)                    // public
+	a.GET("/challenges/:id/positions.geojson", a.GetChallengePositionsGeoJSON)            // public
+	a.GET("/challenges/:id/progress", a.GetChallengeProgress)                             // public
+	a.GET("/challenges/:id/members/:userID/progress", a.GetProgress)                      // public
+	a.GET("/challenges/:id/members/:userID/progress/history", a.GetProgressHistory)       // public
+	a.GET("/challenges/:id/members/:userID/progress.svg", a.GetProgressMap)               // public
+	a.GET("/challenges/:id/members/:userID/badge.svg", a.GetProgressBadge)                // public
+	a.GET("/challenges/:id/leaderboard", a.GetLeaderboard)                                // public
+	a.GET("/challenges/:id/leaderboard/teams", a.GetTeamLeaderboard)                      // public
+	a.GET("/challenges/:id/teams", a.GetTeams)                                            // public
+	a.POST("/challenges/:id/teams", a.PostTeam)                                           // auth
+	a.GET("/challenges/:id/teams/:teamID/progress", a.GetTeamProgress)                    // public
+	a.PUT("/challenges/:id/teams/:teamID/members/:userID", a.SetTeamMembership(true))     // valid user
+	a.DELETE("/challenges/:id/teams/:teamID/members/:userID", a.SetTeamMembership(false)) // valid user
+
+	// Progress routes
+	a.POST("/progress/rebuild", a.AdminAuthFilter, a.RebuildProgress) // admin
+
+	// Route library routes
+	a.GET("/routes", a.GetRoutes)               // public
+	a.POST("/routes", a.PostRoute)              // valid user
+	a.GET("/routes/:routeID", a.GetRoute)       // public
+	a.PATCH("/routes/:routeID", a.PatchRoute)   // valid user
+	a.DELETE("/routes/:routeID", a.DeleteRoute) // valid user
+
+	// Target routes
+	a.GET("/targets/types", a.GetTargetTypes) // public
 
 	// User routes
 	a.GET("/users", a.AdminAuthFilter, a.GetUsers) // admin
@@ -120,8 +168,9 @@ func (a *API) Start() error {
 	a.POST("/users", a.PostUser)                   // valid user
 
 	// User activities routes
-	a.POST("/users/:userID/activities", a.PostUserActivity) // valid user
-	a.GET("/users/:userID/activities", a.GetUserActivities) // public
+	a.POST("/users/:userID/activities", a.PostUserActivity)          // valid user
+	a.GET("/users/:userID/activities", a.GetUserActivities)          // public
+	a.POST("/users/:userID/activities/import", a.ImportUserActivity) // valid user
 
 	// User challenge routes
 	a.PUT("/users/:userID/challenges/:id", a.SetChallengeMembership(true))     // valid user
@@ -11,8 +11,11 @@ import (
 	"github.com/AustinBayley/activity_tracker_api/pkg/activities"
 	"github.com/AustinBayley/activity_tracker_api/pkg/api"
 	"github.com/AustinBayley/activity_tracker_api/pkg/challenges"
+	"github.com/AustinBayley/activity_tracker_api/pkg/progress"
+	"github.com/AustinBayley/activity_tracker_api/pkg/routes"
 	"github.com/AustinBayley/activity_tracker_api/pkg/service"
 	"github.com/AustinBayley/activity_tracker_api/pkg/targets"
+	"github.com/AustinBayley/activity_tracker_api/pkg/tracks"
 	"github.com/AustinBayley/activity_tracker_api/pkg/users"
 	"github.com/gin-gonic/gin"
 	"github.com/rs/zerolog/log"
@@ -29,6 +32,9 @@ var (
 	Users      *users.Service
 	Challenges *challenges.Service
 	Activities *activities.Service
+	Progress   *progress.Service
+	Routes     *routes.Service
+	Tracks     *tracks.Service
 )
 
 func TestMain(m *testing.M) {
@@ -44,9 +50,12 @@ func TestMain(m *testing.M) {
 	db := client.Database("activity_tracker_test")
 
 	acts := activities.New(db.Collection("activities"))
+	trs := tracks.New(db.Collection("tracks"))
 	cds := challenges.NewDetails(db.Collection("challenges"))
 	ms := challenges.NewMemberships(db.Collection("memberships"))
-	cs := challenges.New(cds, ms)
+	ts := challenges.NewTeams(db.Collection("teams"))
+	cs := challenges.New(cds, ms, ts)
+	rs := routes.New(db.Collection("routes"))
 
 	uds := users.NewDetails(db.Collection("users"))
 	us := users.New(
@@ -54,6 +63,14 @@ func TestMain(m *testing.M) {
 		ms,
 		cs,
 		acts,
+		trs,
+	)
+
+	ps := progress.New(
+		progress.NewRecords(db.Collection("progress")),
+		acts,
+		cs,
+		rs,
 	)
 
 	ctx := context.Background()
@@ -69,15 +86,36 @@ func TestMain(m *testing.M) {
 			Msg("failed to setup activities service")
 	}
 
+	if err := trs.Setup(ctx); err != nil {
+		log.Fatal().
+			Err(err).
+			Msg("failed to setup tracks service")
+	}
+
 	if err := us.Setup(ctx); err != nil {
 		log.Fatal().
 			Err(err).
 			Msg("failed to setup users service")
 	}
 
+	if err := rs.Setup(ctx); err != nil {
+		log.Fatal().
+			Err(err).
+			Msg("failed to setup routes service")
+	}
+
+	if err := ps.Setup(ctx); err != nil {
+		log.Fatal().
+			Err(err).
+			Msg("failed to setup progress service")
+	}
+
 	Users = us
 	Challenges = cs
 	Activities = acts
+	Progress = ps
+	Routes = rs
+	Tracks = trs
 
 	API = api.NewAPI(api.NewConfig(
 		api.STG,
@@ -87,6 +125,9 @@ func TestMain(m *testing.M) {
 		acts,
 		cs,
 		us,
+		ps,
+		rs,
+		trs,
 	))
 
 	code := m.Run()
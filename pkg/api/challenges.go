@@ -1,15 +1,18 @@
 package api
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"net/http"
-	"slices"
 	"strings"
+	"time"
 
-	"github.com/AustinBayley/activity_tracker_api/pkg/activities"
 	"github.com/AustinBayley/activity_tracker_api/pkg/challenges"
+	"github.com/AustinBayley/activity_tracker_api/pkg/locations"
+	"github.com/AustinBayley/activity_tracker_api/pkg/progress"
 	"github.com/AustinBayley/activity_tracker_api/pkg/service"
+	"github.com/AustinBayley/activity_tracker_api/pkg/targets"
 	jsonpatch "github.com/evanphx/json-patch/v5"
 	"github.com/gin-gonic/gin"
 	"github.com/rs/zerolog/log"
@@ -99,6 +102,14 @@ func (a *API) PostChallenge(req *gin.Context) {
 		return
 	}
 
+	if !a.checkTargetRoute(req, challenge.Target, actor) {
+		return
+	}
+
+	if !simplifyTarget(req, challenge.Target) {
+		return
+	}
+
 	challenge.CreatedBy = actor.UserID
 	challenge.Members = []service.ID{
 		actor.UserID,
@@ -124,6 +135,8 @@ func (a *API) PostChallenge(req *gin.Context) {
 	}
 	challenge.ID = cID
 
+	a.refreshChallengeProgress(req, cID)
+
 	req.JSON(http.StatusCreated, challenge)
 }
 
@@ -274,6 +287,14 @@ func (a *API) PatchChallenge(req *gin.Context) {
 			return
 		}
 
+		if !a.checkTargetRoute(req, challenge.Target, actor) {
+			return
+		}
+
+		if !simplifyTarget(req, challenge.Target) {
+			return
+		}
+
 		operations = append(operations, challenges.SetDetailOperation{
 			Detail: challenge,
 		})
@@ -305,6 +326,8 @@ func (a *API) PatchChallenge(req *gin.Context) {
 		return
 	}
 
+	a.refreshChallengeProgress(req, challengeID)
+
 	req.JSON(http.StatusNoContent, nil)
 }
 
@@ -373,6 +396,8 @@ func (a *API) DeleteChallenge(req *gin.Context) {
 		return
 	}
 
+	a.refreshChallengeProgress(req, sID)
+
 	req.JSON(http.StatusNoContent, nil)
 }
 
@@ -395,7 +420,110 @@ func (a *API) GetProgress(req *gin.Context) {
 
 	userID := service.ID(uID)
 
-	challenge := challenges.Challenge{}
+	record, err := a.progress.Get(req, service.ID(id), userID)
+	if err != nil {
+		if errors.Is(err, progress.ErrNotFound) {
+			req.JSON(http.StatusNotFound, ErrorResponse{
+				Cause: NotFound,
+			})
+			return
+		}
+
+		log.Error().
+			Err(err).
+			Str("userID", string(userID)).
+			Str("challengeID", id).
+			Msg("error getting challenge progress")
+
+		req.JSON(http.StatusInternalServerError, ErrorResponse{
+			Cause: InternalServer,
+		})
+		return
+	}
+
+	res, err := progressResponse(record)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Str("userID", string(userID)).
+			Str("challengeID", id).
+			Msg("error encoding challenge progress")
+
+		req.JSON(http.StatusInternalServerError, ErrorResponse{
+			Cause: InternalServer,
+		})
+		return
+	}
+
+	req.JSON(http.StatusOK, res)
+}
+
+// progressResponse returns the fields of a member's progress at the top level, as the progress
+// endpoint has always served them, alongside the window and the metadata of its record.
+func progressResponse(record *progress.Record) (map[string]interface{}, error) {
+	b, err := json.Marshal(record.Progress)
+	if err != nil {
+		return nil, err
+	}
+
+	res := map[string]interface{}{}
+	if err := json.Unmarshal(b, &res); err != nil {
+		return nil, err
+	}
+
+	for key, value := range map[string]interface{}{
+		"challenge":  record.Challenge,
+		"user":       record.User,
+		"window":     record.Window,
+		"reachedAt":  record.ReachedAt,
+		"projection": record.Projection,
+		"updated":    record.Updated,
+	} {
+		// Fields of the progress itself take precedence
+		if _, ok := res[key]; !ok {
+			res[key] = value
+		}
+	}
+
+	return res, nil
+}
+
+const (
+	// maxRouteSize is the largest GPX file that can be uploaded as the route of a challenge.
+	maxRouteSize = 32 << 20
+)
+
+type RouteUploadOptions struct {
+	// Tolerance is how far in metres the route may be simplified, see targets.Route.
+	Tolerance float64 `form:"tolerance"`
+}
+
+// PutChallengeRoute replaces the route of a challenge with the track or route of an uploaded GPX
+// file, either as the "file" field of a multipart form or as the request body.
+func (a *API) PutChallengeRoute(req *gin.Context) {
+	id := req.Param("id")
+	if id == "" {
+		req.JSON(http.StatusBadRequest, ErrorResponse{
+			Cause: "challenge ID not supplied",
+		})
+		return
+	}
+
+	opts := RouteUploadOptions{}
+	if err := req.BindQuery(&opts); err != nil {
+		log.Error().
+			Err(err).
+			Msg("error binding query parameters")
+	}
+
+	if opts.Tolerance < 0 {
+		req.JSON(http.StatusBadRequest, ErrorResponse{
+			Cause: "tolerance must not be negative",
+		})
+		return
+	}
+
+	challenge := challenges.Detail{}
 	if err := a.challenges.Get(req, service.ID(id), &challenge); err != nil {
 		log.Error().
 			Err(err).
@@ -415,24 +543,129 @@ func (a *API) GetProgress(req *gin.Context) {
 		return
 	}
 
-	if !slices.Contains(challenge.Members, userID) {
-		req.JSON(http.StatusNotFound, ErrorResponse{
-			Cause: NotFound,
+	actor, ok := GetActorContext(req)
+	if !ok {
+		log.Error().
+			Str("ID", challenge.ID.ConvertID()).
+			Msg("failed to get actor from context")
+
+		req.JSON(http.StatusUnauthorized, ErrorResponse{
+			Cause: Unauthorised,
+		})
+		return
+	}
+
+	if challenge.CreatedBy != actor.UserID && !actor.Admin {
+		log.Error().
+			Str("ID", challenge.ID.ConvertID()).
+			Msg("actor is not allowed to update challenge")
+
+		req.JSON(http.StatusForbidden, ErrorResponse{
+			Cause: "not allowed to update challenge",
+		})
+		return
+	}
+
+	target, ok := challenge.Target.(*targets.RouteMovingTarget)
+	if !ok {
+		req.JSON(http.StatusConflict, ErrorResponse{
+			Cause: "challenge target does not have a route",
+		})
+		return
+	}
+
+	// Only read the file once the actor is known to be allowed to replace the route
+	data, _, ok := readUpload(req, maxRouteSize)
+	if !ok {
+		return
+	}
+
+	gpx, err := locations.ParseGPX(bytes.NewReader(data))
+	if err != nil {
+		req.JSON(http.StatusUnprocessableEntity, ErrorResponse{
+			Cause: "invalid gpx file",
+		})
+		return
+	}
+
+	waypoints, err := gpx.Waypoints()
+	if err != nil {
+		req.JSON(http.StatusUnprocessableEntity, ErrorResponse{
+			Cause: "gpx file has no track or route points",
 		})
 		return
 	}
 
-	opts := activities.ListOptions{
-		User: &userID,
+	// The uploaded route replaces any route referenced from the library
+	target.RouteID = nil
+	target.Route = targets.Route{
+		Waypoints: waypoints,
+		Tolerance: opts.Tolerance,
+	}
+	if !simplifyTarget(req, target) {
+		return
 	}
 
-	acts := []activities.Activity{}
-	if err := a.activities.List(req, opts, &acts); err != nil {
+	if err := a.challenges.Update(req, challenges.SetDetailOperation{Detail: challenge}); err != nil {
+		log.Error().
+			Err(err).
+			Str("ID", id).
+			Msg("error updating challenge route")
+
+		switch {
+		case errors.Is(err, challenges.ErrNotFound):
+			req.JSON(http.StatusNotFound, ErrorResponse{
+				Cause: NotFound,
+			})
+			return
+		case errors.Is(err, challenges.ErrValidation):
+			req.JSON(http.StatusUnprocessableEntity, ErrorResponse{
+				Cause: Validation,
+			})
+			return
+		}
+
+		req.JSON(http.StatusInternalServerError, ErrorResponse{
+			Cause: InternalServer,
+		})
+		return
+	}
+
+	a.refreshChallengeProgress(req, challenge.ID)
+
+	req.JSON(http.StatusOK, challenge)
+}
+
+// GetChallengeProgress returns the pooled progress of every member of a collective challenge and
+// each member's share of it.
+func (a *API) GetChallengeProgress(req *gin.Context) {
+	id := req.Param("id")
+	if id == "" {
+		req.JSON(http.StatusBadRequest, ErrorResponse{
+			Cause: "challenge ID not supplied",
+		})
+		return
+	}
+
+	record, err := a.progress.Collective(req, service.ID(id))
+	if err != nil {
+		switch {
+		case errors.Is(err, progress.ErrNotFound):
+			req.JSON(http.StatusNotFound, ErrorResponse{
+				Cause: NotFound,
+			})
+			return
+		case errors.Is(err, progress.ErrNotCollective):
+			req.JSON(http.StatusConflict, ErrorResponse{
+				Cause: "challenge is not collective",
+			})
+			return
+		}
+
 		log.Error().
 			Err(err).
-			Str("userID", string(userID)).
 			Str("challengeID", id).
-			Msg("error listing user activities")
+			Msg("error getting collective challenge progress")
 
 		req.JSON(http.StatusInternalServerError, ErrorResponse{
 			Cause: InternalServer,
@@ -440,13 +673,157 @@ func (a *API) GetProgress(req *gin.Context) {
 		return
 	}
 
-	progress, err := challenge.Target.Evaluate(req, acts)
+	req.JSON(http.StatusOK, record)
+}
+
+type LeaderboardEntry struct {
+	Rank int         `json:"rank"`
+	User PartialUser `json:"user"`
+	progress.Record
+}
+
+// GetLeaderboard ranks the members of a challenge by their progress. Members with the same
+// progress are ranked by who reached it first.
+func (a *API) GetLeaderboard(req *gin.Context) {
+	id := req.Param("id")
+	if id == "" {
+		req.JSON(http.StatusBadRequest, ErrorResponse{
+			Cause: "challenge ID not supplied",
+		})
+		return
+	}
+
+	rawOpts := ListOptions{}
+	if err := req.BindQuery(&rawOpts); err != nil {
+		log.Error().
+			Err(err).
+			Msg("error binding query parameters")
+	}
+
+	challenge := challenges.Detail{}
+	if err := a.challenges.Get(req, service.ID(id), &challenge); err != nil {
+		log.Error().
+			Err(err).
+			Str("challengeID", id).
+			Msg("error getting challenge")
+
+		if errors.Is(err, challenges.ErrNotFound) {
+			req.JSON(http.StatusNotFound, ErrorResponse{
+				Cause: NotFound,
+			})
+			return
+		}
+
+		req.JSON(http.StatusInternalServerError, ErrorResponse{
+			Cause: InternalServer,
+		})
+		return
+	}
+
+	if rawOpts.Max <= 0 {
+		rawOpts.Max = 10
+	}
+
+	skip := max(rawOpts.Page-1, 0) * rawOpts.Max
+	opts := progress.NewRecordListOptions()
+	opts.SetChallenge(challenge.ID).
+		SetLimit(rawOpts.Max).
+		SetSkip(skip)
+
+	records := []progress.Record{}
+	if err := a.progress.List(req, opts, &records); err != nil {
+		log.Error().
+			Err(err).
+			Str("challengeID", id).
+			Msg("error listing challenge progress")
+
+		req.JSON(http.StatusInternalServerError, ErrorResponse{
+			Cause: InternalServer,
+		})
+		return
+	}
+
+	entries := make([]LeaderboardEntry, 0, len(records))
+	for i, r := range records {
+		entry := LeaderboardEntry{
+			Rank:   int(skip) + i + 1,
+			User:   PartialUser{ID: r.User},
+			Record: r,
+		}
+
+		// Users who have since been deleted are left with just their ID
+		if err := a.users.Get(req, r.User, &entry.User); err != nil {
+			log.Warn().
+				Err(err).
+				Str("userID", string(r.User)).
+				Msg("error getting leaderboard user")
+		}
+
+		entries = append(entries, entry)
+	}
+
+	req.JSON(http.StatusOK, entries)
+}
+
+type HistoryOptions struct {
+	Interval targets.Period `form:"interval,default=day"`
+	Timezone string         `form:"timezone"`
+}
+
+// GetProgressHistory returns a member's cumulative progress at the end of each day or week.
+func (a *API) GetProgressHistory(req *gin.Context) {
+	id := req.Param("id")
+	if id == "" {
+		req.JSON(http.StatusBadRequest, ErrorResponse{
+			Cause: "challenge ID not supplied",
+		})
+		return
+	}
+
+	uID := req.Param("userID")
+	if uID == "" {
+		req.JSON(http.StatusBadRequest, ErrorResponse{
+			Cause: "user ID not supplied",
+		})
+		return
+	}
+
+	opts := HistoryOptions{}
+	if err := req.BindQuery(&opts); err != nil {
+		log.Error().
+			Err(err).
+			Msg("error binding query parameters")
+	}
+
+	if opts.Interval != targets.Day && opts.Interval != targets.Week {
+		req.JSON(http.StatusBadRequest, ErrorResponse{
+			Cause: "interval must be day or week",
+		})
+		return
+	}
+
+	loc, err := time.LoadLocation(opts.Timezone)
+	if err != nil {
+		req.JSON(http.StatusBadRequest, ErrorResponse{
+			Cause: "invalid timezone",
+		})
+		return
+	}
+
+	history, err := a.progress.History(req, service.ID(id), service.ID(uID), opts.Interval, loc)
 	if err != nil {
+		if errors.Is(err, progress.ErrNotFound) {
+			req.JSON(http.StatusNotFound, ErrorResponse{
+				Cause: NotFound,
+			})
+			return
+		}
+
 		log.Error().
 			Err(err).
-			Str("userID", string(userID)).
+			Str("userID", uID).
 			Str("challengeID", id).
-			Msg("error evaluating challenge progress")
+			Msg("error getting challenge progress history")
 
 		req.JSON(http.StatusInternalServerError, ErrorResponse{
 			Cause: InternalServer,
@@ -454,5 +831,5 @@ func (a *API) GetProgress(req *gin.Context) {
 		return
 	}
 
-	req.JSON(http.StatusOK, progress)
+	req.JSON(http.StatusOK, history)
 }
@@ -1,13 +1,18 @@
 package api_test
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"io"
+	"mime/multipart"
 	"net/http/httptest"
+	"os"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/AustinBayley/activity_tracker_api/pkg/activities"
 	"github.com/AustinBayley/activity_tracker_api/pkg/api"
 	"github.com/AustinBayley/activity_tracker_api/pkg/challenges"
 	"github.com/AustinBayley/activity_tracker_api/pkg/service"
@@ -142,6 +147,133 @@ func TestUpdateChallenge(t *testing.T) {
 	}
 }
 
+func TestPutChallengeRoute(t *testing.T) {
+	challenge, cleanup, err := CreateTestChallenge(context.Background(), "Route Challenge")
+	if err != nil {
+		t.Fatalf("failed to create test challenge: %v", err)
+	}
+	t.Cleanup(cleanup)
+
+	gpx, err := os.ReadFile("testdata/route.gpx")
+	if err != nil {
+		t.Fatalf("failed to read test gpx: %v", err)
+	}
+
+	body := &bytes.Buffer{}
+	form := multipart.NewWriter(body)
+	part, err := form.CreateFormFile("file", "route.gpx")
+	if err != nil {
+		t.Fatalf("failed to create form file: %v", err)
+	}
+	_, _ = part.Write(gpx)
+	_ = form.Close()
+
+	req := httptest.NewRequest("PUT", "/challenges/"+string(challenge.ID)+"/route", body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	recorder := httptest.NewRecorder()
+	ctx := gin.CreateTestContextOnly(recorder, API.Engine)
+	ctx.AddParam("id", string(challenge.ID))
+	ctx.Request = req
+
+	ctx.Set(api.UserCtxKey, api.RequestContext{
+		UserID: service.ID("test_user"),
+	})
+
+	API.PutChallengeRoute(ctx)
+
+	if ctx.Writer.Status() != 200 {
+		t.Fatalf("expected status 200, got %d", ctx.Writer.Status())
+	}
+
+	var updated challenges.Challenge
+	if err := Challenges.Get(ctx, challenge.ID, &updated); err != nil {
+		t.Fatalf("failed to get updated challenge: %v", err)
+	}
+
+	target, ok := updated.Target.(*targets.RouteMovingTarget)
+	if !ok {
+		t.Fatalf("expected route moving target, got %T", updated.Target)
+	}
+
+	if len(target.Route.Waypoints) != 6 || target.Route.First().Name != "Land's End" {
+		t.Errorf("expected 6 waypoints from Land's End, got %+v", target.Route.Waypoints)
+	}
+
+	if target.TotalDistance != target.Route.Distance() || target.TotalDistance < 900 {
+		t.Errorf("expected total distance of route, got %f", target.TotalDistance)
+	}
+}
+
+// readCounter records how much of a request body has been read.
+type readCounter struct {
+	r io.Reader
+	n int
+}
+
+func (c *readCounter) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += n
+	return n, err
+}
+
+// zeros is an endless stream of zero bytes.
+type zeros struct{}
+
+func (zeros) Read(p []byte) (int, error) {
+	clear(p)
+	return len(p), nil
+}
+
+func TestPutChallengeRouteRejected(t *testing.T) {
+	challenge, cleanup, err := CreateTestChallenge(context.Background(), "Rejected Route Challenge")
+	if err != nil {
+		t.Fatalf("failed to create test challenge: %v", err)
+	}
+	t.Cleanup(cleanup)
+
+	for _, tt := range []struct {
+		name   string
+		actor  *api.RequestContext
+		status int
+		// read reports whether the body should have been read at all
+		read bool
+	}{
+		{"anonymous", nil, 401, false},
+		{"not the creator", &api.RequestContext{UserID: "someone_else"}, 403, false},
+		{"too large", &api.RequestContext{UserID: "test_user"}, 413, true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			body := &readCounter{r: io.LimitReader(zeros{}, 64<<20)}
+
+			req := httptest.NewRequest("PUT", "/challenges/"+string(challenge.ID)+"/route", body)
+			req.Header.Set("Content-Type", "application/gpx+xml")
+			recorder := httptest.NewRecorder()
+			ctx := gin.CreateTestContextOnly(recorder, API.Engine)
+			ctx.AddParam("id", string(challenge.ID))
+			ctx.Request = req
+
+			if tt.actor != nil {
+				ctx.Set(api.UserCtxKey, *tt.actor)
+			}
+
+			API.PutChallengeRoute(ctx)
+
+			if ctx.Writer.Status() != tt.status {
+				t.Errorf("expected status %d, got %d", tt.status, ctx.Writer.Status())
+			}
+
+			if read := body.n > 0; read != tt.read {
+				t.Errorf("expected body to be read %v, read %d bytes", tt.read, body.n)
+			}
+
+			// Never more than the limit and form overhead is read
+			if body.n > 40<<20 {
+				t.Errorf("expected at most 40 MB to be read, read %d bytes", body.n)
+			}
+		})
+	}
+}
+
 func TestDeleteChallenge(t *testing.T) {
 	title := "Delete Challenge"
 	challenge, cleanup, _ := CreateTestChallenge(context.Background(), title)
@@ -170,3 +302,417 @@ func TestDeleteChallenge(t *testing.T) {
 		t.Errorf("expected error getting deleted challenge, got none")
 	}
 }
+
+func TestCreateDurationChallenge(t *testing.T) {
+	email := "testcreatedurationchallenge@user.com"
+	_, callback, err := CreateTestUser(context.Background(), email)
+	if err != nil {
+		t.Fatalf("failed to create test user: %v", err)
+	}
+
+	body := `{
+		"name": "Duration Challenge",
+		"description": "Spend 20 hours on the bike",
+		"start_date": "2025-10-01T00:00:00Z",
+		"end_date": "2025-10-31T00:00:00Z",
+		"target": {"type": "durationTarget", "duration": 1200, "activityTypes": ["cycling"]}
+	}`
+
+	req := httptest.NewRequest("POST", "/challenges", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Auth-Request-Email", email)
+
+	recorder := httptest.NewRecorder()
+	ctx := gin.CreateTestContextOnly(recorder, API.Engine)
+	ctx.Request = req
+
+	API.ActorFilter(ctx)
+	API.PostChallenge(ctx)
+
+	if ctx.Writer.Status() != 201 {
+		t.Fatalf("expected status 201, got %d", ctx.Writer.Status())
+	}
+
+	var createdChallenge challenges.Challenge
+	if err := json.NewDecoder(recorder.Body).Decode(&createdChallenge); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	t.Cleanup(func() {
+		_ = callback()
+		_ = Challenges.Delete(ctx, createdChallenge.ID)
+	})
+
+	if _, ok := createdChallenge.Target.(*targets.DurationTarget); !ok {
+		t.Errorf("expected target to be of type DurationTarget, got %T", createdChallenge.Target)
+	}
+}
+
+func TestGetProgressWindow(t *testing.T) {
+	ctx := context.Background()
+	userID := service.ID("progress_user")
+
+	challenge := challenges.Challenge{
+		Detail: challenges.Detail{
+			BaseDetail: challenges.BaseDetail{
+				Name:        "Progress Challenge",
+				Description: "A test challenge",
+				CreatedBy:   userID,
+				StartDate:   time.Now().Add(-20 * time.Hour),
+				EndDate:     time.Now().Add(20 * time.Hour),
+			},
+			Target: &targets.DistanceTarget{
+				BaseTarget: targets.BaseTarget{
+					TargetType: targets.DistanceTargetType,
+				},
+				Distance: 100,
+			},
+		},
+		Members: []service.ID{
+			userID,
+		},
+	}
+
+	cID, err := Challenges.Create(ctx, &challenge)
+	if err != nil {
+		t.Fatalf("failed to create test challenge: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = Challenges.Delete(ctx, cID)
+		_ = Activities.Delete(ctx, activities.ActivityDeleteOpts{User: &userID})
+	})
+
+	for _, start := range []time.Time{
+		time.Now().Add(-30 * time.Hour),
+		time.Now().Add(-2 * time.Hour),
+	} {
+		activity := activities.Activity{
+			Type:   activities.Running,
+			UserID: userID,
+			Value:  10,
+			Start:  start,
+			End:    start.Add(time.Hour),
+		}
+		if _, err := Activities.Create(ctx, &activity); err != nil {
+			t.Fatalf("failed to create test activity: %v", err)
+		}
+	}
+
+	req := httptest.NewRequest("GET", "/challenges/"+string(cID)+"/members/"+string(userID)+"/progress", nil)
+	recorder := httptest.NewRecorder()
+	gctx := gin.CreateTestContextOnly(recorder, API.Engine)
+	gctx.AddParam("id", string(cID))
+	gctx.AddParam("userID", string(userID))
+	gctx.Request = req
+
+	API.GetProgress(gctx)
+
+	if gctx.Writer.Status() != 200 {
+		t.Fatalf("expected status 200, got %d", gctx.Writer.Status())
+	}
+
+	// Progress fields are served at the top level, next to the window and record metadata
+	var res struct {
+		targets.DistanceTargetProgress
+		Window     targets.Window      `json:"window"`
+		Projection *targets.Projection `json:"projection"`
+	}
+	if err := json.NewDecoder(recorder.Body).Decode(&res); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	if res.DistanceCovered != 10 {
+		t.Errorf("expected distance covered 10, got %f", res.DistanceCovered)
+	}
+
+	if res.Window.Start.Unix() != challenge.StartDate.Unix() {
+		t.Errorf("expected window to start at %s, got %s", challenge.StartDate, res.Window.Start)
+	}
+
+	if res.Projection == nil || res.Projection.Remaining != 90 {
+		t.Errorf("expected projection with 90 km remaining, got %+v", res.Projection)
+	}
+}
+
+func TestGetLeaderboard(t *testing.T) {
+	ctx := context.Background()
+	members := []service.ID{"leader_a", "leader_b", "leader_c"}
+
+	challenge := challenges.Challenge{
+		Detail: challenges.Detail{
+			BaseDetail: challenges.BaseDetail{
+				Name:        "Leaderboard Challenge",
+				Description: "A test challenge",
+				CreatedBy:   members[0],
+				StartDate:   time.Now().Add(-20 * time.Hour),
+				EndDate:     time.Now().Add(20 * time.Hour),
+			},
+			Target: &targets.DistanceTarget{
+				BaseTarget: targets.BaseTarget{
+					TargetType: targets.DistanceTargetType,
+				},
+				Distance: 10,
+			},
+		},
+		Members: members,
+	}
+
+	cID, err := Challenges.Create(ctx, &challenge)
+	if err != nil {
+		t.Fatalf("failed to create test challenge: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = Challenges.Delete(ctx, cID)
+		for _, m := range members {
+			_ = Activities.Delete(ctx, activities.ActivityDeleteOpts{User: &m})
+		}
+	})
+
+	// leader_c and leader_a both complete the challenge, but leader_c does so first
+	logs := []struct {
+		user  service.ID
+		value float64
+		start time.Time
+	}{
+		{"leader_a", 10, time.Now().Add(-4 * time.Hour)},
+		{"leader_b", 5, time.Now().Add(-10 * time.Hour)},
+		{"leader_c", 12, time.Now().Add(-8 * time.Hour)},
+	}
+	for _, l := range logs {
+		activity := activities.Activity{
+			Type:   activities.Running,
+			UserID: l.user,
+			Value:  l.value,
+			Start:  l.start,
+			End:    l.start.Add(time.Hour),
+		}
+		if _, err := Activities.Create(ctx, &activity); err != nil {
+			t.Fatalf("failed to create test activity: %v", err)
+		}
+	}
+
+	if err := Progress.RefreshChallenge(ctx, cID); err != nil {
+		t.Fatalf("failed to refresh challenge progress: %v", err)
+	}
+
+	req := httptest.NewRequest("GET", "/challenges/"+string(cID)+"/leaderboard?max=2&page=1", nil)
+	recorder := httptest.NewRecorder()
+	gctx := gin.CreateTestContextOnly(recorder, API.Engine)
+	gctx.AddParam("id", string(cID))
+	gctx.Request = req
+
+	API.GetLeaderboard(gctx)
+
+	if gctx.Writer.Status() != 200 {
+		t.Fatalf("expected status 200, got %d", gctx.Writer.Status())
+	}
+
+	var entries []struct {
+		Rank int             `json:"rank"`
+		User api.PartialUser `json:"user"`
+	}
+	if err := json.NewDecoder(recorder.Body).Decode(&entries); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+
+	for i, expected := range []service.ID{"leader_c", "leader_a"} {
+		if entries[i].User.ID != expected || entries[i].Rank != i+1 {
+			t.Errorf("expected %s at rank %d, got %s at rank %d", expected, i+1, entries[i].User.ID, entries[i].Rank)
+		}
+	}
+}
+
+func TestGetProgressHistory(t *testing.T) {
+	ctx := context.Background()
+	userID := service.ID("history_user")
+	start := time.Now().Add(-72 * time.Hour)
+
+	challenge := challenges.Challenge{
+		Detail: challenges.Detail{
+			BaseDetail: challenges.BaseDetail{
+				Name:        "History Challenge",
+				Description: "A test challenge",
+				CreatedBy:   userID,
+				StartDate:   start,
+				EndDate:     time.Now().Add(72 * time.Hour),
+			},
+			Target: &targets.DistanceTarget{
+				BaseTarget: targets.BaseTarget{
+					TargetType: targets.DistanceTargetType,
+				},
+				Distance: 100,
+			},
+		},
+		Members: []service.ID{
+			userID,
+		},
+	}
+
+	cID, err := Challenges.Create(ctx, &challenge)
+	if err != nil {
+		t.Fatalf("failed to create test challenge: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = Challenges.Delete(ctx, cID)
+		_ = Activities.Delete(ctx, activities.ActivityDeleteOpts{User: &userID})
+	})
+
+	activity := activities.Activity{
+		Type:   activities.Running,
+		UserID: userID,
+		Value:  10,
+		Start:  start.Add(time.Hour),
+		End:    start.Add(2 * time.Hour),
+	}
+	if _, err := Activities.Create(ctx, &activity); err != nil {
+		t.Fatalf("failed to create test activity: %v", err)
+	}
+
+	req := httptest.NewRequest("GET", "/challenges/"+string(cID)+"/members/"+string(userID)+"/progress/history?interval=day", nil)
+	recorder := httptest.NewRecorder()
+	gctx := gin.CreateTestContextOnly(recorder, API.Engine)
+	gctx.AddParam("id", string(cID))
+	gctx.AddParam("userID", string(userID))
+	gctx.Request = req
+
+	API.GetProgressHistory(gctx)
+
+	if gctx.Writer.Status() != 200 {
+		t.Fatalf("expected status 200, got %d", gctx.Writer.Status())
+	}
+
+	var res struct {
+		Points []struct {
+			Progress targets.DistanceTargetProgress `json:"progress"`
+		} `json:"points"`
+	}
+	if err := json.NewDecoder(recorder.Body).Decode(&res); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	if len(res.Points) < 3 {
+		t.Fatalf("expected at least 3 daily points, got %d", len(res.Points))
+	}
+
+	if last := res.Points[len(res.Points)-1]; last.Progress.DistanceCovered != 10 {
+		t.Errorf("expected latest distance covered 10, got %f", last.Progress.DistanceCovered)
+	}
+}
+
+func TestGetChallengeProgress(t *testing.T) {
+	ctx := context.Background()
+	members := []service.ID{"club_a", "club_b"}
+
+	challenge := challenges.Challenge{
+		Detail: challenges.Detail{
+			BaseDetail: challenges.BaseDetail{
+				Name:        "Collective Challenge",
+				Description: "A test challenge",
+				CreatedBy:   members[0],
+				StartDate:   time.Now().Add(-20 * time.Hour),
+				EndDate:     time.Now().Add(20 * time.Hour),
+				Collective:  true,
+			},
+			Target: &targets.DistanceTarget{
+				BaseTarget: targets.BaseTarget{
+					TargetType: targets.DistanceTargetType,
+				},
+				Distance: 100,
+			},
+		},
+		Members: members,
+	}
+
+	cID, err := Challenges.Create(ctx, &challenge)
+	if err != nil {
+		t.Fatalf("failed to create test challenge: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = Challenges.Delete(ctx, cID)
+		for _, m := range members {
+			_ = Activities.Delete(ctx, activities.ActivityDeleteOpts{User: &m})
+		}
+	})
+
+	for user, value := range map[service.ID]float64{"club_a": 30, "club_b": 10} {
+		activity := activities.Activity{
+			Type:   activities.Running,
+			UserID: user,
+			Value:  value,
+			Start:  time.Now().Add(-4 * time.Hour),
+			End:    time.Now().Add(-3 * time.Hour),
+		}
+		if _, err := Activities.Create(ctx, &activity); err != nil {
+			t.Fatalf("failed to create test activity: %v", err)
+		}
+	}
+
+	req := httptest.NewRequest("GET", "/challenges/"+string(cID)+"/progress", nil)
+	recorder := httptest.NewRecorder()
+	gctx := gin.CreateTestContextOnly(recorder, API.Engine)
+	gctx.AddParam("id", string(cID))
+	gctx.Request = req
+
+	API.GetChallengeProgress(gctx)
+
+	if gctx.Writer.Status() != 200 {
+		t.Fatalf("expected status 200, got %d", gctx.Writer.Status())
+	}
+
+	var res struct {
+		Progress struct {
+			Percent float64 `json:"percent"`
+		} `json:"progress"`
+		Members []struct {
+			User  service.ID `json:"user"`
+			Share float64    `json:"share"`
+		} `json:"members"`
+	}
+	if err := json.NewDecoder(recorder.Body).Decode(&res); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	if res.Progress.Percent != 40 {
+		t.Errorf("expected collective progress of 40%%, got %v", res.Progress.Percent)
+	}
+
+	if len(res.Members) != 2 || res.Members[0].User != "club_a" || res.Members[0].Share != 75 {
+		t.Errorf("expected club_a to contribute 75%%, got %+v", res.Members)
+	}
+}
+
+func TestLeaderboardEvaluatesMissingRecords(t *testing.T) {
+	// The challenge is created without its progress being evaluated
+	challenge, cleanup, err := CreateTestChallenge(context.Background(), "Unevaluated Challenge")
+	if err != nil {
+		t.Fatalf("failed to create test challenge: %v", err)
+	}
+	t.Cleanup(cleanup)
+
+	req := httptest.NewRequest("GET", "/challenges/"+string(challenge.ID)+"/leaderboard?max=0", nil)
+	recorder := httptest.NewRecorder()
+	gctx := gin.CreateTestContextOnly(recorder, API.Engine)
+	gctx.AddParam("id", string(challenge.ID))
+	gctx.Request = req
+
+	API.GetLeaderboard(gctx)
+
+	if gctx.Writer.Status() != 200 {
+		t.Fatalf("expected status 200, got %d", gctx.Writer.Status())
+	}
+
+	var entries []struct {
+		User api.PartialUser `json:"user"`
+	}
+	if err := json.NewDecoder(recorder.Body).Decode(&entries); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	if len(entries) != 1 || entries[0].User.ID != "1234" {
+		t.Errorf("expected the member to be evaluated and listed, got %+v", entries)
+	}
+}
@@ -0,0 +1,178 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/AustinBayley/activity_tracker_api/pkg/challenges"
+	"github.com/AustinBayley/activity_tracker_api/pkg/locations"
+	"github.com/AustinBayley/activity_tracker_api/pkg/progress"
+	"github.com/AustinBayley/activity_tracker_api/pkg/service"
+	"github.com/AustinBayley/activity_tracker_api/pkg/targets"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// writeGeoJSON responds with the feature collection as GeoJSON.
+func writeGeoJSON(req *gin.Context, fc locations.FeatureCollection) {
+	b, err := json.Marshal(fc)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Msg("error marshalling geojson")
+
+		req.JSON(http.StatusInternalServerError, ErrorResponse{
+			Cause: InternalServer,
+		})
+		return
+	}
+
+	req.Data(http.StatusOK, locations.GeoJSONContentType, b)
+}
+
+// getRouteChallenge gets a challenge with a route, responding with an error if it can't.
+func (a *API) getRouteChallenge(req *gin.Context) (*challenges.Detail, *targets.RouteMovingTarget, bool) {
+	id := req.Param("id")
+	if id == "" {
+		req.JSON(http.StatusBadRequest, ErrorResponse{
+			Cause: "challenge ID not supplied",
+		})
+		return nil, nil, false
+	}
+
+	challenge := challenges.Detail{}
+	if err := a.challenges.Get(req, service.ID(id), &challenge); err != nil {
+		log.Error().
+			Err(err).
+			Str("challengeID", id).
+			Msg("error getting challenge")
+
+		if errors.Is(err, challenges.ErrNotFound) {
+			req.JSON(http.StatusNotFound, ErrorResponse{
+				Cause: NotFound,
+			})
+			return nil, nil, false
+		}
+
+		req.JSON(http.StatusInternalServerError, ErrorResponse{
+			Cause: InternalServer,
+		})
+		return nil, nil, false
+	}
+
+	target, ok := challenge.Target.(*targets.RouteMovingTarget)
+	if !ok {
+		req.JSON(http.StatusConflict, ErrorResponse{
+			Cause: "challenge target does not have a route",
+		})
+		return nil, nil, false
+	}
+
+	if err := target.Resolve(targets.WithRouteResolver(req, a.routes)); err != nil {
+		log.Error().
+			Err(err).
+			Str("challengeID", id).
+			Msg("error resolving challenge route")
+
+		req.JSON(http.StatusInternalServerError, ErrorResponse{
+			Cause: InternalServer,
+		})
+		return nil, nil, false
+	}
+
+	return &challenge, target, true
+}
+
+// GetChallengeRouteGeoJSON returns the route of a challenge as a LineString feature, followed by
+// a Point feature for each of its checkpoints.
+func (a *API) GetChallengeRouteGeoJSON(req *gin.Context) {
+	challenge, target, ok := a.getRouteChallenge(req)
+	if !ok {
+		return
+	}
+
+	if len(target.Route.Waypoints) == 0 {
+		writeGeoJSON(req, locations.NewFeatureCollection())
+		return
+	}
+
+	features := target.Route.Waypoints.RouteFeatures(target.Route.Index(), map[string]interface{}{
+		"challenge":     challenge.ID,
+		"name":          challenge.Name,
+		"totalDistance": target.TotalDistance,
+	})
+
+	writeGeoJSON(req, locations.NewFeatureCollection(features...))
+}
+
+// routeProgress returns the progress of a member along a route, whether it was just evaluated
+// or read back from storage.
+func routeProgress(p targets.Progress) (targets.RouteMovingTargetProgress, error) {
+	switch p := p.(type) {
+	case targets.RouteMovingTargetProgress:
+		return p, nil
+	case progress.Stored:
+		rp := targets.RouteMovingTargetProgress{}
+		err := p.Decode(&rp)
+		return rp, err
+	}
+	return targets.RouteMovingTargetProgress{}, progress.ErrInvalid
+}
+
+// GetChallengePositionsGeoJSON returns the current position of each member along the route of a
+// challenge as a Point feature, ranked by their progress.
+func (a *API) GetChallengePositionsGeoJSON(req *gin.Context) {
+	challenge, _, ok := a.getRouteChallenge(req)
+	if !ok {
+		return
+	}
+
+	opts := progress.NewRecordListOptions()
+	opts.SetChallenge(challenge.ID)
+
+	records := []progress.Record{}
+	if err := a.progress.List(req, opts, &records); err != nil {
+		log.Error().
+			Err(err).
+			Str("challengeID", challenge.ID.ConvertID()).
+			Msg("error listing challenge progress")
+
+		req.JSON(http.StatusInternalServerError, ErrorResponse{
+			Cause: InternalServer,
+		})
+		return
+	}
+
+	features := make([]locations.Feature, 0, len(records))
+	for i, r := range records {
+		p, err := routeProgress(r.Progress)
+		if err != nil {
+			log.Warn().
+				Err(err).
+				Str("challengeID", challenge.ID.ConvertID()).
+				Str("userID", string(r.User)).
+				Msg("error reading route progress")
+			continue
+		}
+
+		// Users who have since been deleted are left with just their ID
+		user := PartialUser{ID: r.User}
+		if err := a.users.Get(req, r.User, &user); err != nil {
+			log.Warn().
+				Err(err).
+				Str("userID", string(r.User)).
+				Msg("error getting user position")
+		}
+
+		features = append(features, locations.NewFeature(locations.NewPoint(p.Location.LatLng), map[string]interface{}{
+			"rank":            i + 1,
+			"user":            user,
+			"percent":         p.Percent,
+			"distanceCovered": p.DistanceCovered,
+			"location":        p.Location.Name,
+		}))
+	}
+
+	writeGeoJSON(req, locations.NewFeatureCollection(features...))
+}
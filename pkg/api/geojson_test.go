@@ -0,0 +1,149 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/AustinBayley/activity_tracker_api/pkg/activities"
+	"github.com/AustinBayley/activity_tracker_api/pkg/challenges"
+	"github.com/AustinBayley/activity_tracker_api/pkg/locations"
+	"github.com/AustinBayley/activity_tracker_api/pkg/service"
+	"github.com/AustinBayley/activity_tracker_api/pkg/targets"
+	"github.com/gin-gonic/gin"
+)
+
+type testFeatureCollection struct {
+	Type     string `json:"type"`
+	Features []struct {
+		Geometry struct {
+			Type        string          `json:"type"`
+			Coordinates json.RawMessage `json:"coordinates"`
+		} `json:"geometry"`
+		Properties map[string]interface{} `json:"properties"`
+	} `json:"features"`
+}
+
+func createRouteChallenge(t *testing.T, members []service.ID) service.ID {
+	t.Helper()
+	ctx := context.Background()
+
+	challenge := challenges.Challenge{
+		Detail: challenges.Detail{
+			BaseDetail: challenges.BaseDetail{
+				Name:        "GeoJSON Challenge",
+				Description: "A test challenge",
+				CreatedBy:   members[0],
+				StartDate:   time.Now().Add(-20 * time.Hour),
+				EndDate:     time.Now().Add(20 * time.Hour),
+			},
+			Target: &targets.RouteMovingTarget{
+				BaseTarget: targets.BaseTarget{
+					TargetType: targets.RouteMovingTargetType,
+				},
+				Route: targets.Route{
+					Waypoints: locations.Waypoints{
+						{LatLng: locations.LatLng{Lat: 51.45, Lng: -2.59}, Name: "Bristol", Checkpoint: true},
+						{LatLng: locations.LatLng{Lat: 51.38, Lng: -2.36}, Name: "Bath", Checkpoint: true},
+					},
+				},
+			},
+		},
+		Members: members,
+	}
+
+	cID, err := Challenges.Create(ctx, &challenge)
+	if err != nil {
+		t.Fatalf("failed to create test challenge: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = Challenges.Delete(ctx, cID)
+		for _, m := range members {
+			_ = Activities.Delete(ctx, activities.ActivityDeleteOpts{User: &m})
+		}
+	})
+
+	return cID
+}
+
+func TestGetChallengeRouteGeoJSON(t *testing.T) {
+	cID := createRouteChallenge(t, []service.ID{"geojson_user"})
+
+	req := httptest.NewRequest("GET", "/challenges/"+string(cID)+"/route.geojson", nil)
+	recorder := httptest.NewRecorder()
+	gctx := gin.CreateTestContextOnly(recorder, API.Engine)
+	gctx.AddParam("id", string(cID))
+	gctx.Request = req
+
+	API.GetChallengeRouteGeoJSON(gctx)
+
+	if gctx.Writer.Status() != 200 {
+		t.Fatalf("expected status 200, got %d", gctx.Writer.Status())
+	}
+
+	if ct := recorder.Header().Get("Content-Type"); ct != locations.GeoJSONContentType {
+		t.Errorf("expected content type %s, got %s", locations.GeoJSONContentType, ct)
+	}
+
+	var fc testFeatureCollection
+	if err := json.NewDecoder(recorder.Body).Decode(&fc); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	if len(fc.Features) != 3 {
+		t.Fatalf("expected a route and 2 checkpoints, got %d features", len(fc.Features))
+	}
+
+	if fc.Features[0].Geometry.Type != "LineString" || fc.Features[1].Properties["name"] != "Bristol" {
+		t.Errorf("unexpected features %+v", fc.Features)
+	}
+}
+
+func TestGetChallengePositionsGeoJSON(t *testing.T) {
+	ctx := context.Background()
+	userID := service.ID("geojson_position_user")
+	cID := createRouteChallenge(t, []service.ID{userID})
+
+	activity := activities.Activity{
+		Type:   activities.Running,
+		UserID: userID,
+		Value:  5,
+		Start:  time.Now().Add(-4 * time.Hour),
+		End:    time.Now().Add(-3 * time.Hour),
+	}
+	if _, err := Activities.Create(ctx, &activity); err != nil {
+		t.Fatalf("failed to create test activity: %v", err)
+	}
+
+	if err := Progress.RefreshChallenge(ctx, cID); err != nil {
+		t.Fatalf("failed to refresh challenge progress: %v", err)
+	}
+
+	req := httptest.NewRequest("GET", "/challenges/"+string(cID)+"/positions.geojson", nil)
+	recorder := httptest.NewRecorder()
+	gctx := gin.CreateTestContextOnly(recorder, API.Engine)
+	gctx.AddParam("id", string(cID))
+	gctx.Request = req
+
+	API.GetChallengePositionsGeoJSON(gctx)
+
+	if gctx.Writer.Status() != 200 {
+		t.Fatalf("expected status 200, got %d", gctx.Writer.Status())
+	}
+
+	var fc testFeatureCollection
+	if err := json.NewDecoder(recorder.Body).Decode(&fc); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	if len(fc.Features) != 1 || fc.Features[0].Geometry.Type != "Point" {
+		t.Fatalf("expected a single point, got %+v", fc.Features)
+	}
+
+	user, _ := fc.Features[0].Properties["user"].(map[string]interface{})
+	if user["id"] != string(userID) || fc.Features[0].Properties["distanceCovered"] != 5.0 {
+		t.Errorf("unexpected position properties %v", fc.Features[0].Properties)
+	}
+}
@@ -0,0 +1,178 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/AustinBayley/activity_tracker_api/pkg/activities"
+	"github.com/AustinBayley/activity_tracker_api/pkg/service"
+	"github.com/AustinBayley/activity_tracker_api/pkg/tracks"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// maxImportSize is the largest activity file that can be imported.
+	maxImportSize = 32 << 20
+)
+
+type ImportOptions struct {
+	// Format is the format of the file, detected from its name or content if not given.
+	Format tracks.Format `form:"format"`
+	// Type overrides the activity type given by the file.
+	Type activities.ActivityType `form:"type"`
+	// Track stores the recorded positions of the activity as well as the activity.
+	Track bool `form:"track"`
+}
+
+// ImportUserActivity creates an activity for a user from a GPX, TCX or FIT file, either as the
+// "file" field of a multipart form or as the request body. Its distance is measured along the
+// recorded positions, and its times and type are read from the file.
+func (a *API) ImportUserActivity(req *gin.Context) {
+	id := req.Param("userID")
+	if id == "" {
+		req.JSON(http.StatusBadRequest, ErrorResponse{
+			Cause: "user ID not supplied",
+		})
+		return
+	}
+	userID := service.ID(id)
+
+	actor, ok := GetActorContext(req)
+	if !ok {
+		log.Error().
+			Msg("failed to get actor from context")
+
+		req.JSON(http.StatusUnauthorized, ErrorResponse{
+			Cause: Unauthorised,
+		})
+		return
+	}
+
+	if actor.UserID != userID && !actor.Admin {
+		log.Error().
+			Str("userID", userID.ConvertID()).
+			Msg("actor is not allowed to create activity for user")
+
+		req.JSON(http.StatusForbidden, ErrorResponse{
+			Cause: "not allowed to create activity for user",
+		})
+		return
+	}
+
+	opts := ImportOptions{}
+	if err := req.BindQuery(&opts); err != nil {
+		log.Error().
+			Err(err).
+			Msg("error binding query parameters")
+	}
+
+	data, filename, ok := readUpload(req, maxImportSize)
+	if !ok {
+		return
+	}
+
+	format := opts.Format
+	if format == "" {
+		detected, err := tracks.DetectFormat(filename, data)
+		if err != nil {
+			req.JSON(http.StatusUnsupportedMediaType, ErrorResponse{
+				Cause: "file must be gpx, tcx or fit",
+			})
+			return
+		}
+		format = detected
+	}
+
+	recording, err := tracks.Parse(data, format)
+	if err != nil {
+		switch {
+		case errors.Is(err, tracks.ErrUnknownFormat):
+			req.JSON(http.StatusUnsupportedMediaType, ErrorResponse{
+				Cause: "file must be gpx, tcx or fit",
+			})
+		case errors.Is(err, tracks.ErrNoTimes):
+			req.JSON(http.StatusUnprocessableEntity, ErrorResponse{
+				Cause: "file has no start and end times",
+			})
+		default:
+			req.JSON(http.StatusUnprocessableEntity, ErrorResponse{
+				Cause: fmt.Sprintf("invalid %s file", format),
+			})
+		}
+		return
+	}
+
+	activity := recording.Activity(userID)
+	if opts.Type != "" {
+		activity.Type = opts.Type
+	}
+
+	if activity.Type == "" {
+		req.JSON(http.StatusUnprocessableEntity, ErrorResponse{
+			Cause: "activity type not recognised, supply it as type",
+		})
+		return
+	}
+
+	if opts.Track && (len(recording.Points) == 0 || len(recording.Points) > tracks.MaxPoints) {
+		req.JSON(http.StatusUnprocessableEntity, ErrorResponse{
+			Cause: fmt.Sprintf("track must have between 1 and %d positions", tracks.MaxPoints),
+		})
+		return
+	}
+
+	aID, err := a.activities.Create(req, &activity)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Str("userID", string(userID)).
+			Msg("error creating activity")
+
+		if errors.Is(err, activities.ErrValidation) {
+			req.JSON(http.StatusUnprocessableEntity, ErrorResponse{
+				Cause: Validation,
+			})
+			return
+		}
+
+		req.JSON(http.StatusInternalServerError, ErrorResponse{
+			Cause: InternalServer,
+		})
+		return
+	}
+	activity.ID = aID
+
+	if opts.Track {
+		track := tracks.Track{
+			Activity: aID,
+			User:     userID,
+			Points:   recording.Points,
+		}
+
+		if _, err := a.tracks.Create(req, &track); err != nil {
+			log.Error().
+				Err(err).
+				Str("activityID", aID.ConvertID()).
+				Msg("error creating activity track")
+
+			// Don't leave the activity behind without the track that was asked for
+			if err := a.activities.Delete(req, activities.ActivityDeleteOpts{ID: &aID}); err != nil {
+				log.Error().
+					Err(err).
+					Str("activityID", aID.ConvertID()).
+					Msg("error deleting activity")
+			}
+
+			req.JSON(http.StatusInternalServerError, ErrorResponse{
+				Cause: InternalServer,
+			})
+			return
+		}
+	}
+
+	a.refreshUserProgress(req, userID)
+
+	req.JSON(http.StatusCreated, activity)
+}
@@ -0,0 +1,110 @@
+package api_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"mime/multipart"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/AustinBayley/activity_tracker_api/pkg/activities"
+	"github.com/AustinBayley/activity_tracker_api/pkg/api"
+	"github.com/AustinBayley/activity_tracker_api/pkg/tracks"
+	"github.com/gin-gonic/gin"
+)
+
+func importActivity(t *testing.T, target string, body *bytes.Buffer, contentType string) (*httptest.ResponseRecorder, *gin.Context) {
+	t.Helper()
+
+	req := httptest.NewRequest("POST", target, body)
+	req.Header.Set("Content-Type", contentType)
+
+	recorder := httptest.NewRecorder()
+	gctx := gin.CreateTestContextOnly(recorder, API.Engine)
+	gctx.Request = req
+	gctx.AddParam("userID", "import_user")
+	gctx.Set(api.UserCtxKey, api.RequestContext{
+		UserID: "import_user",
+	})
+
+	API.ImportUserActivity(gctx)
+
+	return recorder, gctx
+}
+
+func TestImportActivity(t *testing.T) {
+	ctx := context.Background()
+
+	data, err := os.ReadFile("testdata/run.gpx")
+	if err != nil {
+		t.Fatalf("failed to read gpx file: %v", err)
+	}
+
+	body := &bytes.Buffer{}
+	form := multipart.NewWriter(body)
+	part, err := form.CreateFormFile("file", "run.gpx")
+	if err != nil {
+		t.Fatalf("failed to create form file: %v", err)
+	}
+	_, _ = part.Write(data)
+	_ = form.Close()
+
+	recorder, gctx := importActivity(t, "/users/import_user/activities/import?track=true", body, form.FormDataContentType())
+	if gctx.Writer.Status() != 201 {
+		t.Fatalf("expected status 201, got %d: %s", gctx.Writer.Status(), recorder.Body.String())
+	}
+
+	created := activities.Activity{}
+	if err := json.NewDecoder(recorder.Body).Decode(&created); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = Activities.Delete(ctx, activities.ActivityDeleteOpts{ID: &created.ID})
+		_ = Tracks.Delete(ctx, tracks.TrackDeleteOpts{Activity: &created.ID})
+	})
+
+	if created.Type != activities.Running || created.UserID != "import_user" {
+		t.Errorf("expected a running activity of import_user, got %+v", created)
+	}
+
+	if created.Value <= 0 || created.End.Sub(created.Start).Minutes() != 8 {
+		t.Errorf("expected an 8 minute activity with a distance, got %+v", created)
+	}
+
+	track := tracks.Track{}
+	if err := Tracks.Get(ctx, created.ID, &track); err != nil {
+		t.Fatalf("expected track to be stored, got %v", err)
+	}
+
+	if len(track.Points) != 5 {
+		t.Errorf("expected 5 track points, got %d", len(track.Points))
+	}
+}
+
+func TestImportActivityErrors(t *testing.T) {
+	data, err := os.ReadFile("testdata/run.gpx")
+	if err != nil {
+		t.Fatalf("failed to read gpx file: %v", err)
+	}
+
+	for _, tt := range []struct {
+		name   string
+		target string
+		body   []byte
+		status int
+	}{
+		{"unknown format", "/users/import_user/activities/import", []byte("hello"), 415},
+		{"invalid file", "/users/import_user/activities/import?format=tcx", data, 422},
+		{"unknown type", "/users/import_user/activities/import", bytes.Replace(data, []byte("running"), []byte("rowing"), 1), 422},
+		{"too large", "/users/import_user/activities/import", make([]byte, 33<<20), 413},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			_, gctx := importActivity(t, tt.target, bytes.NewBuffer(tt.body), "application/octet-stream")
+			if gctx.Writer.Status() != tt.status {
+				t.Errorf("expected status %d, got %d", tt.status, gctx.Writer.Status())
+			}
+		})
+	}
+}
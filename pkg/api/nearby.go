@@ -0,0 +1,145 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/AustinBayley/activity_tracker_api/pkg/challenges"
+	"github.com/AustinBayley/activity_tracker_api/pkg/locations"
+	"github.com/AustinBayley/activity_tracker_api/pkg/routes"
+	"github.com/AustinBayley/activity_tracker_api/pkg/service"
+	"github.com/AustinBayley/activity_tracker_api/pkg/targets"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+type NearbyOptions struct {
+	Lat      *float64 `form:"lat"`
+	Lng      *float64 `form:"lng"`
+	RadiusKm float64  `form:"radiusKm,default=25"`
+	Max      int64    `form:"max,default=10"`
+}
+
+// NearbyChallenge is a challenge whose route passes near a point.
+type NearbyChallenge struct {
+	challenges.Detail `json:",inline"`
+	// DistanceKm is how far the nearest waypoint of the route is from the point.
+	DistanceKm float64            `json:"distanceKm"`
+	Nearest    locations.Waypoint `json:"nearest"`
+}
+
+// GetNearbyChallenges finds public challenges that haven't ended whose route starts, ends or
+// passes through a waypoint within the radius of a point, nearest first. Candidates are found by
+// the H3 cells their routes were indexed with when saved, then filtered by their exact distance.
+func (a *API) GetNearbyChallenges(req *gin.Context) {
+	opts := NearbyOptions{}
+	if err := req.ShouldBindQuery(&opts); err != nil {
+		req.JSON(http.StatusBadRequest, ErrorResponse{
+			Cause: "invalid query parameters",
+		})
+		return
+	}
+
+	if opts.Lat == nil || opts.Lng == nil {
+		req.JSON(http.StatusBadRequest, ErrorResponse{
+			Cause: "lat and lng must be supplied",
+		})
+		return
+	}
+
+	latlng := locations.LatLng{Lat: *opts.Lat, Lng: *opts.Lng}
+	cells, err := locations.CellsWithin(latlng, opts.RadiusKm)
+	if err != nil {
+		switch {
+		case errors.Is(err, locations.ErrInvalidLatLng):
+			req.JSON(http.StatusBadRequest, ErrorResponse{
+				Cause: "lat must be between -90 and 90 and lng between -180 and 180",
+			})
+			return
+		case errors.Is(err, locations.ErrInvalidRadius):
+			req.JSON(http.StatusBadRequest, ErrorResponse{
+				Cause: fmt.Sprintf("radiusKm must be greater than 0 and at most %d", locations.MaxNearbyKm),
+			})
+			return
+		}
+
+		log.Error().
+			Err(err).
+			Msg("error finding cells within radius")
+
+		req.JSON(http.StatusInternalServerError, ErrorResponse{
+			Cause: InternalServer,
+		})
+		return
+	}
+
+	// Challenges may reference routes from the library rather than having their own
+	rs := []routes.Route{}
+	if err := a.routes.List(req, *routes.NewListOptions().SetCells(cells), &rs); err != nil {
+		log.Error().
+			Err(err).
+			Msg("error listing nearby routes")
+
+		req.JSON(http.StatusInternalServerError, ErrorResponse{
+			Cause: InternalServer,
+		})
+		return
+	}
+
+	routeIDs := make([]service.ID, 0, len(rs))
+	for _, r := range rs {
+		routeIDs = append(routeIDs, r.ID)
+	}
+
+	cs := []challenges.Detail{}
+	if err := a.challenges.ListNearby(req, cells, routeIDs, &cs); err != nil {
+		log.Error().
+			Err(err).
+			Msg("error listing nearby challenges")
+
+		req.JSON(http.StatusInternalServerError, ErrorResponse{
+			Cause: InternalServer,
+		})
+		return
+	}
+
+	ctx := targets.WithRouteResolver(req, a.routes)
+	nearby := []NearbyChallenge{}
+	for _, c := range cs {
+		target, ok := c.Target.(*targets.RouteMovingTarget)
+		if !ok {
+			continue
+		}
+
+		if err := target.Resolve(ctx); err != nil {
+			log.Warn().
+				Err(err).
+				Str("challengeID", c.ID.ConvertID()).
+				Msg("error resolving challenge route")
+			continue
+		}
+
+		nearest, km, err := target.Route.Waypoints.Nearest(latlng)
+		if err != nil || km > opts.RadiusKm {
+			continue
+		}
+
+		nearby = append(nearby, NearbyChallenge{
+			Detail:     c,
+			DistanceKm: km,
+			Nearest:    nearest,
+		})
+	}
+
+	sort.SliceStable(nearby, func(i, j int) bool {
+		return nearby[i].DistanceKm < nearby[j].DistanceKm
+	})
+
+	if opts.Max > 0 && int64(len(nearby)) > opts.Max {
+		nearby = nearby[:opts.Max]
+	}
+
+	req.JSON(http.StatusOK, nearby)
+}
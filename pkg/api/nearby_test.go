@@ -0,0 +1,153 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/AustinBayley/activity_tracker_api/pkg/challenges"
+	"github.com/AustinBayley/activity_tracker_api/pkg/locations"
+	"github.com/AustinBayley/activity_tracker_api/pkg/service"
+	"github.com/AustinBayley/activity_tracker_api/pkg/targets"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func TestGetNearbyChallenges(t *testing.T) {
+	ctx := context.Background()
+
+	challenge := challenges.Challenge{
+		Detail: challenges.Detail{
+			BaseDetail: challenges.BaseDetail{
+				Name:        "Nearby Challenge",
+				Description: "A test challenge",
+				CreatedBy:   service.ID("nearby_user"),
+				StartDate:   time.Now().Add(-20 * time.Hour),
+				EndDate:     time.Now().Add(20 * time.Hour),
+				Public:      true,
+			},
+			Target: &targets.RouteMovingTarget{
+				BaseTarget: targets.BaseTarget{
+					TargetType: targets.RouteMovingTargetType,
+				},
+				Route: targets.Route{
+					Waypoints: locations.Waypoints{
+						{LatLng: locations.LatLng{Lat: 51.45, Lng: -2.59}, Name: "Bristol"},
+						{LatLng: locations.LatLng{Lat: 51.38, Lng: -2.36}, Name: "Bath"},
+					},
+				},
+			},
+		},
+		Members: []service.ID{"nearby_user"},
+	}
+
+	cID, err := Challenges.Create(ctx, &challenge)
+	if err != nil {
+		t.Fatalf("failed to create test challenge: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = Challenges.Delete(ctx, cID)
+	})
+
+	tests := []struct {
+		name  string
+		query string
+		found bool
+	}{
+		{"near bath", "lat=51.39&lng=-2.35&radiusKm=5", true},
+		{"edinburgh", "lat=55.95&lng=-3.19&radiusKm=50", false},
+		{"just out of range", "lat=51.45&lng=-2.80&radiusKm=10", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			gctx := gin.CreateTestContextOnly(recorder, API.Engine)
+			gctx.Request = httptest.NewRequest("GET", "/challenges/nearby?"+tt.query, nil)
+
+			API.GetNearbyChallenges(gctx)
+
+			if gctx.Writer.Status() != 200 {
+				t.Fatalf("expected status 200, got %d", gctx.Writer.Status())
+			}
+
+			// Decoded without the challenge, as its target can't be decoded outside of a Detail
+			nearby := []struct {
+				ID         service.ID         `json:"id"`
+				DistanceKm float64            `json:"distanceKm"`
+				Nearest    locations.Waypoint `json:"nearest"`
+			}{}
+			if err := json.NewDecoder(recorder.Body).Decode(&nearby); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+
+			found := false
+			for _, c := range nearby {
+				if c.ID == cID {
+					found = true
+					if c.Nearest.Name != "Bath" {
+						t.Errorf("expected nearest waypoint to be Bath, got %s", c.Nearest.Name)
+					}
+				}
+			}
+
+			if found != tt.found {
+				t.Errorf("expected challenge found to be %t, got %t", tt.found, found)
+			}
+		})
+	}
+
+	recorder := httptest.NewRecorder()
+	gctx := gin.CreateTestContextOnly(recorder, API.Engine)
+	gctx.Request = httptest.NewRequest("GET", "/challenges/nearby?lat=51.39&lng=-2.35&radiusKm=1000", nil)
+
+	API.GetNearbyChallenges(gctx)
+
+	if gctx.Writer.Status() != 400 {
+		t.Errorf("expected status 400 for a radius that is too large, got %d", gctx.Writer.Status())
+	}
+}
+
+func TestSetupIndexesExistingRoutes(t *testing.T) {
+	ctx := context.Background()
+	waypoints := locations.Waypoints{
+		{LatLng: locations.LatLng{Lat: 51.45, Lng: -2.59}},
+		{LatLng: locations.LatLng{Lat: 51.38, Lng: -2.36}},
+	}
+
+	// Challenges saved before routes were indexed have no cells
+	collection := Activities.Database().Collection("challenges_unindexed")
+	t.Cleanup(func() {
+		_ = collection.Drop(ctx)
+	})
+
+	if _, err := collection.InsertOne(ctx, bson.D{
+		{Key: "_id", Value: "unindexed"},
+		{Key: "target", Value: bson.D{
+			{Key: "type", Value: targets.RouteMovingTargetType},
+			{Key: "route", Value: bson.D{{Key: "waypoints", Value: waypoints}}},
+		}},
+	}); err != nil {
+		t.Fatalf("failed to insert test challenge: %v", err)
+	}
+
+	if err := challenges.NewDetails(collection).Setup(ctx); err != nil {
+		t.Fatalf("failed to setup challenges: %v", err)
+	}
+
+	cells, err := waypoints.Cells()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	n, err := collection.CountDocuments(ctx, bson.D{{Key: "target.route.cells", Value: cells[0]}})
+	if err != nil {
+		t.Fatalf("failed to count challenges: %v", err)
+	}
+
+	if n != 1 {
+		t.Errorf("expected the challenge's route to be indexed by cell %s", cells[0])
+	}
+}
@@ -0,0 +1,78 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/AustinBayley/activity_tracker_api/pkg/progress"
+
+	"github.com/AustinBayley/activity_tracker_api/pkg/service"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// refreshUserProgress updates the stored progress of a user after their activities change.
+// Failures are logged rather than returned as the change itself has succeeded and progress
+// can be rebuilt.
+func (a *API) refreshUserProgress(ctx context.Context, userID service.ID) {
+	if err := a.progress.RefreshUser(ctx, userID); err != nil {
+		log.Warn().
+			Err(err).
+			Str("userID", string(userID)).
+			Msg("failed to refresh user progress")
+	}
+}
+
+// refreshChallengeProgress updates the stored progress of every member of a challenge after
+// the challenge or its memberships change.
+func (a *API) refreshChallengeProgress(ctx context.Context, challengeID service.ID) {
+	if err := a.progress.RefreshChallenge(ctx, challengeID); err != nil {
+		log.Warn().
+			Err(err).
+			Str("challengeID", string(challengeID)).
+			Msg("failed to refresh challenge progress")
+	}
+}
+
+// refreshRouteProgress updates the stored progress of every challenge using a route from the
+// library after the route changes.
+func (a *API) refreshRouteProgress(ctx context.Context, routeID service.ID) {
+	if err := a.progress.RefreshRoute(ctx, routeID); err != nil {
+		log.Warn().
+			Err(err).
+			Str("routeID", string(routeID)).
+			Msg("failed to refresh route progress")
+	}
+}
+
+// refreshMemberProgress updates the stored progress of a user in a challenge after they join or leave it.
+func (a *API) refreshMemberProgress(ctx context.Context, challengeID service.ID, userID service.ID) {
+	if _, err := a.progress.RefreshMember(ctx, challengeID, userID); err != nil && !errors.Is(err, progress.ErrNotFound) {
+		log.Warn().
+			Err(err).
+			Str("challengeID", string(challengeID)).
+			Str("userID", string(userID)).
+			Msg("failed to refresh member progress")
+	}
+}
+
+// RebuildProgress discards all stored progress and re-evaluates every challenge membership.
+func (a *API) RebuildProgress(req *gin.Context) {
+	count, err := a.progress.Rebuild(req)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Int("rebuilt", count).
+			Msg("error rebuilding progress")
+
+		req.JSON(http.StatusInternalServerError, ErrorResponse{
+			Cause: InternalServer,
+		})
+		return
+	}
+
+	req.JSON(http.StatusOK, gin.H{
+		"rebuilt": count,
+	})
+}
@@ -0,0 +1,83 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/AustinBayley/activity_tracker_api/pkg/api"
+	"github.com/AustinBayley/activity_tracker_api/pkg/challenges"
+	"github.com/AustinBayley/activity_tracker_api/pkg/service"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func TestRebuildProgress(t *testing.T) {
+	_, cleanup, err := CreateTestChallenge(context.Background(), "Rebuild Challenge")
+	if err != nil {
+		t.Fatalf("failed to create test challenge: %v", err)
+	}
+	t.Cleanup(cleanup)
+
+	req := httptest.NewRequest("POST", "/progress/rebuild", nil)
+	recorder := httptest.NewRecorder()
+	ctx := gin.CreateTestContextOnly(recorder, API.Engine)
+	ctx.Request = req
+
+	ctx.Set(api.UserCtxKey, api.RequestContext{
+		UserID: service.ID("test_admin"),
+		Admin:  true,
+	})
+
+	API.AdminAuthFilter(ctx)
+	API.RebuildProgress(ctx)
+
+	if ctx.Writer.Status() != 200 {
+		t.Fatalf("expected status 200, got %d", ctx.Writer.Status())
+	}
+
+	var res struct {
+		Rebuilt int `json:"rebuilt"`
+	}
+	if err := json.NewDecoder(recorder.Body).Decode(&res); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	if res.Rebuilt < 1 {
+		t.Errorf("expected at least 1 record to be rebuilt, got %d", res.Rebuilt)
+	}
+}
+
+func TestRefreshUserSkipsDeletedChallenges(t *testing.T) {
+	ctx := context.Background()
+
+	challenge, cleanup, err := CreateTestChallenge(ctx, "Refresh Challenge")
+	if err != nil {
+		t.Fatalf("failed to create test challenge: %v", err)
+	}
+	t.Cleanup(cleanup)
+
+	// A membership left behind by a challenge that has since been deleted
+	orphan := challenges.Membership{
+		Challenge: service.NewID(),
+		User:      "1234",
+		Created:   time.Now(),
+	}
+	memberships := Activities.Database().Collection("memberships")
+	if _, err := memberships.InsertOne(ctx, orphan); err != nil {
+		t.Fatalf("failed to create orphaned membership: %v", err)
+	}
+	t.Cleanup(func() {
+		_, _ = memberships.DeleteOne(ctx, bson.D{{Key: "challenge", Value: orphan.Challenge.ConvertID()}})
+	})
+
+	if err := Progress.RefreshUser(ctx, "1234"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if _, err := Progress.Get(ctx, challenge.ID, "1234"); err != nil {
+		t.Errorf("expected progress in the remaining challenge, got %v", err)
+	}
+}
@@ -0,0 +1,421 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/AustinBayley/activity_tracker_api/pkg/challenges"
+	"github.com/AustinBayley/activity_tracker_api/pkg/locations"
+	"github.com/AustinBayley/activity_tracker_api/pkg/routes"
+	"github.com/AustinBayley/activity_tracker_api/pkg/service"
+	"github.com/AustinBayley/activity_tracker_api/pkg/targets"
+	jsonpatch "github.com/evanphx/json-patch/v5"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// getRoute gets a route from the library, responding with an error if it can't or the actor
+// isn't allowed to see it.
+func (a *API) getRoute(req *gin.Context) (*routes.Route, bool) {
+	id := req.Param("routeID")
+	if id == "" {
+		req.JSON(http.StatusBadRequest, ErrorResponse{
+			Cause: "route ID not supplied",
+		})
+		return nil, false
+	}
+
+	route := routes.Route{}
+	if err := a.routes.Get(req, service.ID(id), &route); err != nil {
+		if errors.Is(err, routes.ErrNotFound) {
+			req.JSON(http.StatusNotFound, ErrorResponse{
+				Cause: NotFound,
+			})
+			return nil, false
+		}
+
+		log.Error().
+			Err(err).
+			Str("routeID", id).
+			Msg("error getting route")
+
+		req.JSON(http.StatusInternalServerError, ErrorResponse{
+			Cause: InternalServer,
+		})
+		return nil, false
+	}
+
+	// Private routes are hidden rather than forbidden so their existence isn't revealed
+	actor, _ := GetActorContext(req)
+	if !route.VisibleTo(actor.UserID) && !actor.Admin {
+		req.JSON(http.StatusNotFound, ErrorResponse{
+			Cause: NotFound,
+		})
+		return nil, false
+	}
+
+	return &route, true
+}
+
+// checkTargetRoute ensures a target only references a route the actor may use, responding with
+// an error if it doesn't.
+func (a *API) checkTargetRoute(req *gin.Context, target targets.Target, actor RequestContext) bool {
+	t, ok := target.(*targets.RouteMovingTarget)
+	if !ok || t.RouteID == nil {
+		return true
+	}
+
+	route := routes.Route{}
+	if err := a.routes.Get(req, *t.RouteID, &route); err != nil {
+		if errors.Is(err, routes.ErrNotFound) {
+			req.JSON(http.StatusUnprocessableEntity, ErrorResponse{
+				Cause: "route not found",
+			})
+			return false
+		}
+
+		log.Error().
+			Err(err).
+			Str("routeID", t.RouteID.ConvertID()).
+			Msg("error getting route")
+
+		req.JSON(http.StatusInternalServerError, ErrorResponse{
+			Cause: InternalServer,
+		})
+		return false
+	}
+
+	if !route.VisibleTo(actor.UserID) && !actor.Admin {
+		req.JSON(http.StatusForbidden, ErrorResponse{
+			Cause: "not allowed to use route",
+		})
+		return false
+	}
+
+	return true
+}
+
+// simplifyTarget simplifies the route of a target before it is saved, responding with an error
+// if it can't be.
+func simplifyTarget(req *gin.Context, target targets.Target) bool {
+	t, ok := target.(*targets.RouteMovingTarget)
+	if !ok {
+		return true
+	}
+
+	if err := t.Simplify(); err != nil {
+		if errors.Is(err, locations.ErrTooManyWaypoints) {
+			req.JSON(http.StatusUnprocessableEntity, ErrorResponse{
+				Cause: fmt.Sprintf("route must have at most %d named waypoints and checkpoints", locations.MaxWaypoints),
+			})
+			return false
+		}
+
+		log.Error().
+			Err(err).
+			Msg("error simplifying route")
+
+		req.JSON(http.StatusInternalServerError, ErrorResponse{
+			Cause: InternalServer,
+		})
+		return false
+	}
+
+	return true
+}
+
+// GetRoutes lists the routes in the library the actor can see, without their waypoints.
+func (a *API) GetRoutes(req *gin.Context) {
+	rawOpts := ListOptions{}
+	if err := req.BindQuery(&rawOpts); err != nil {
+		log.Error().
+			Err(err).
+			Msg("error binding query parameters")
+	}
+
+	opts := routes.NewListOptions().
+		SetLimit(rawOpts.Max).
+		SetSkip(max(rawOpts.Page-1, 0) * rawOpts.Max)
+
+	actor, _ := GetActorContext(req)
+	if !actor.Admin {
+		opts.SetVisibleTo(actor.UserID)
+	}
+
+	if owner := req.Query("owner"); owner != "" {
+		opts.SetOwner(service.ID(owner))
+	}
+
+	rs := []routes.Route{}
+	if err := a.routes.List(req, *opts, &rs); err != nil {
+		log.Error().
+			Err(err).
+			Msg("error listing routes")
+
+		req.JSON(http.StatusInternalServerError, ErrorResponse{
+			Cause: InternalServer,
+		})
+		return
+	}
+
+	req.JSON(http.StatusOK, rs)
+}
+
+func (a *API) GetRoute(req *gin.Context) {
+	route, ok := a.getRoute(req)
+	if !ok {
+		return
+	}
+
+	req.JSON(http.StatusOK, route)
+}
+
+func (a *API) PostRoute(req *gin.Context) {
+	var route routes.Route
+	if err := req.BindJSON(&route); err != nil {
+		log.Error().
+			Err(err).
+			Msg("error binding JSON to route")
+
+		req.JSON(http.StatusBadRequest, ErrorResponse{
+			Cause: "invalid request body",
+		})
+		return
+	}
+
+	actor, ok := GetActorContext(req)
+	if !ok || actor.UserID == "" {
+		log.Error().
+			Msg("failed to get actor from context")
+
+		req.JSON(http.StatusUnauthorized, ErrorResponse{
+			Cause: Unauthorised,
+		})
+		return
+	}
+
+	route.Owner = actor.UserID
+	if route.Visibility == "" {
+		route.Visibility = routes.Private
+	}
+
+	id, err := a.routes.Create(req, &route)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Msg("error creating route")
+
+		if errors.Is(err, routes.ErrValidation) {
+			req.JSON(http.StatusUnprocessableEntity, ErrorResponse{
+				Cause: Validation,
+			})
+			return
+		}
+
+		req.JSON(http.StatusInternalServerError, ErrorResponse{
+			Cause: InternalServer,
+		})
+		return
+	}
+	route.ID = id
+
+	req.JSON(http.StatusCreated, route)
+}
+
+func (a *API) PatchRoute(req *gin.Context) {
+	stored, ok := a.getRoute(req)
+	if !ok {
+		return
+	}
+
+	actor, ok := GetActorContext(req)
+	if !ok {
+		log.Error().
+			Str("ID", stored.ID.ConvertID()).
+			Msg("failed to get actor from context")
+
+		req.JSON(http.StatusUnauthorized, ErrorResponse{
+			Cause: Unauthorised,
+		})
+		return
+	}
+
+	if stored.Owner != actor.UserID && !actor.Admin {
+		log.Error().
+			Str("ID", stored.ID.ConvertID()).
+			Msg("actor is not allowed to update route")
+
+		req.JSON(http.StatusForbidden, ErrorResponse{
+			Cause: "not allowed to update route",
+		})
+		return
+	}
+
+	// Stored route as slice of bytes
+	srbb, err := json.Marshal(stored)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Msg("error marshalling route")
+
+		req.JSON(http.StatusInternalServerError, ErrorResponse{
+			Cause: InternalServer,
+		})
+		return
+	}
+
+	// Get body & store as slice of bytes
+	bb, err := req.GetRawData()
+	if err != nil {
+		req.JSON(http.StatusBadRequest, ErrorResponse{
+			Cause: "error reading request body",
+		})
+		return
+	}
+
+	// Decode requested patch
+	patch, err := jsonpatch.DecodePatch(bb)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Msg("error decoding patch")
+
+		req.JSON(http.StatusUnprocessableEntity, ErrorResponse{
+			Cause: "could not decode patch",
+		})
+		return
+	}
+
+	// Apply patch to stored route to get modified document
+	modified, err := patch.Apply(srbb)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Msg("error applying patch")
+
+		req.JSON(http.StatusUnprocessableEntity, ErrorResponse{
+			Cause: "could not apply patch",
+		})
+		return
+	}
+
+	var route routes.Route
+	if err = json.Unmarshal(modified, &route); err != nil {
+		log.Error().
+			Err(err).
+			Msg("error unmarshalling route")
+
+		req.JSON(http.StatusUnprocessableEntity, ErrorResponse{
+			Cause: "error unmarshalling route",
+		})
+		return
+	}
+
+	// The route's identity and ownership can't be patched
+	route.ID = stored.ID
+	route.Owner = stored.Owner
+	route.CreatedDate = stored.CreatedDate
+
+	if err = a.routes.Update(req, &route); err != nil {
+		log.Error().
+			Err(err).
+			Str("routeID", route.ID.ConvertID()).
+			Msg("error updating route")
+
+		switch {
+		case errors.Is(err, routes.ErrNotFound):
+			req.JSON(http.StatusNotFound, ErrorResponse{
+				Cause: NotFound,
+			})
+			return
+		case errors.Is(err, routes.ErrValidation):
+			req.JSON(http.StatusUnprocessableEntity, ErrorResponse{
+				Cause: Validation,
+			})
+			return
+		}
+
+		req.JSON(http.StatusInternalServerError, ErrorResponse{
+			Cause: InternalServer,
+		})
+		return
+	}
+
+	a.refreshRouteProgress(req, route.ID)
+
+	req.JSON(http.StatusOK, route)
+}
+
+// DeleteRoute removes a route from the library, unless a challenge still uses it.
+func (a *API) DeleteRoute(req *gin.Context) {
+	route, ok := a.getRoute(req)
+	if !ok {
+		return
+	}
+
+	actor, ok := GetActorContext(req)
+	if !ok {
+		log.Error().
+			Str("ID", route.ID.ConvertID()).
+			Msg("failed to get actor from context")
+
+		req.JSON(http.StatusUnauthorized, ErrorResponse{
+			Cause: Unauthorised,
+		})
+		return
+	}
+
+	if route.Owner != actor.UserID && !actor.Admin {
+		log.Error().
+			Str("ID", route.ID.ConvertID()).
+			Msg("actor is not allowed to delete route")
+
+		req.JSON(http.StatusForbidden, ErrorResponse{
+			Cause: "not allowed to delete route",
+		})
+		return
+	}
+
+	cs := []challenges.Detail{}
+	if err := a.challenges.ListByRoute(req, route.ID, &cs); err != nil {
+		log.Error().
+			Err(err).
+			Str("routeID", route.ID.ConvertID()).
+			Msg("error listing challenges using route")
+
+		req.JSON(http.StatusInternalServerError, ErrorResponse{
+			Cause: InternalServer,
+		})
+		return
+	}
+
+	if len(cs) > 0 {
+		req.JSON(http.StatusConflict, ErrorResponse{
+			Cause: "route is used by challenges",
+		})
+		return
+	}
+
+	if err := a.routes.Delete(req, route.ID); err != nil {
+		log.Error().
+			Err(err).
+			Str("routeID", route.ID.ConvertID()).
+			Msg("error deleting route")
+
+		if errors.Is(err, routes.ErrNotFound) {
+			req.JSON(http.StatusNotFound, ErrorResponse{
+				Cause: NotFound,
+			})
+			return
+		}
+
+		req.JSON(http.StatusInternalServerError, ErrorResponse{
+			Cause: InternalServer,
+		})
+		return
+	}
+
+	req.JSON(http.StatusNoContent, nil)
+}
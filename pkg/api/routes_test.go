@@ -0,0 +1,181 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AustinBayley/activity_tracker_api/pkg/activities"
+	"github.com/AustinBayley/activity_tracker_api/pkg/api"
+	"github.com/AustinBayley/activity_tracker_api/pkg/challenges"
+	"github.com/AustinBayley/activity_tracker_api/pkg/locations"
+	"github.com/AustinBayley/activity_tracker_api/pkg/routes"
+	"github.com/AustinBayley/activity_tracker_api/pkg/service"
+	"github.com/AustinBayley/activity_tracker_api/pkg/targets"
+	"github.com/gin-gonic/gin"
+)
+
+func TestCreateRoute(t *testing.T) {
+	ctx := context.Background()
+	owner := service.ID("route_owner")
+
+	route := routes.Route{
+		Name: "Bristol to Bath",
+		Route: targets.Route{
+			Waypoints: locations.Waypoints{
+				{LatLng: locations.LatLng{Lat: 51.45, Lng: -2.59}},
+				{LatLng: locations.LatLng{Lat: 51.38, Lng: -2.36}},
+			},
+		},
+	}
+
+	bb, err := json.Marshal(route)
+	if err != nil {
+		t.Fatalf("failed to marshal route: %v", err)
+	}
+
+	req := httptest.NewRequest("POST", "/routes", strings.NewReader(string(bb)))
+	req.Header.Set("Content-Type", "application/json")
+
+	recorder := httptest.NewRecorder()
+	gctx := gin.CreateTestContextOnly(recorder, API.Engine)
+	gctx.Request = req
+	gctx.Set(api.UserCtxKey, api.RequestContext{
+		UserID: owner,
+	})
+
+	API.PostRoute(gctx)
+
+	if gctx.Writer.Status() != 201 {
+		t.Fatalf("expected status 201, got %d", gctx.Writer.Status())
+	}
+
+	created := routes.Route{}
+	if err := json.NewDecoder(recorder.Body).Decode(&created); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = Routes.Delete(ctx, created.ID)
+	})
+
+	if created.Owner != owner || created.Visibility != routes.Private {
+		t.Errorf("expected a private route owned by %s, got %+v", owner, created)
+	}
+
+	if created.TotalDistance <= 0 {
+		t.Errorf("expected total distance to be calculated, got %f", created.TotalDistance)
+	}
+
+	// Private routes are hidden from other users
+	for _, tt := range []struct {
+		actor  service.ID
+		status int
+	}{
+		{owner, 200},
+		{"someone_else", 404},
+	} {
+		gctx := gin.CreateTestContextOnly(httptest.NewRecorder(), API.Engine)
+		gctx.Request = httptest.NewRequest("GET", "/routes/"+string(created.ID), nil)
+		gctx.AddParam("routeID", string(created.ID))
+		gctx.Set(api.UserCtxKey, api.RequestContext{
+			UserID: tt.actor,
+		})
+
+		API.GetRoute(gctx)
+
+		if gctx.Writer.Status() != tt.status {
+			t.Errorf("expected status %d for %s, got %d", tt.status, tt.actor, gctx.Writer.Status())
+		}
+	}
+}
+
+func TestReferencedRouteProgress(t *testing.T) {
+	ctx := context.Background()
+	userID := service.ID("route_user")
+
+	route := routes.Route{
+		Name:       "Bristol to Bath",
+		Owner:      userID,
+		Visibility: routes.Public,
+		Route: targets.Route{
+			Waypoints: locations.Waypoints{
+				{LatLng: locations.LatLng{Lat: 51.45, Lng: -2.59}},
+				{LatLng: locations.LatLng{Lat: 51.38, Lng: -2.36}},
+			},
+		},
+	}
+
+	routeID, err := Routes.Create(ctx, &route)
+	if err != nil {
+		t.Fatalf("failed to create test route: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = Routes.Delete(ctx, routeID)
+	})
+
+	challenge := challenges.Challenge{
+		Detail: challenges.Detail{
+			BaseDetail: challenges.BaseDetail{
+				Name:        "Library Route Challenge",
+				Description: "A test challenge",
+				CreatedBy:   userID,
+				StartDate:   time.Now().Add(-20 * time.Hour),
+				EndDate:     time.Now().Add(20 * time.Hour),
+			},
+			Target: &targets.RouteMovingTarget{
+				BaseTarget: targets.BaseTarget{
+					TargetType: targets.RouteMovingTargetType,
+				},
+				RouteID: &routeID,
+			},
+		},
+		Members: []service.ID{userID},
+	}
+
+	cID, err := Challenges.Create(ctx, &challenge)
+	if err != nil {
+		t.Fatalf("failed to create test challenge: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = Challenges.Delete(ctx, cID)
+		_ = Activities.Delete(ctx, activities.ActivityDeleteOpts{User: &userID})
+	})
+
+	activity := activities.Activity{
+		Type:   activities.Running,
+		UserID: userID,
+		Value:  5,
+		Start:  time.Now().Add(-4 * time.Hour),
+		End:    time.Now().Add(-3 * time.Hour),
+	}
+	if _, err := Activities.Create(ctx, &activity); err != nil {
+		t.Fatalf("failed to create test activity: %v", err)
+	}
+
+	record, err := Progress.RefreshMember(ctx, cID, userID)
+	if err != nil {
+		t.Fatalf("failed to evaluate progress: %v", err)
+	}
+
+	if expected := 5 / route.TotalDistance * 100; math.Abs(record.Percent-expected) > 1e-6 {
+		t.Errorf("expected %f%% along the referenced route, got %f%%", expected, record.Percent)
+	}
+
+	// Routes can't be deleted while challenges use them
+	gctx := gin.CreateTestContextOnly(httptest.NewRecorder(), API.Engine)
+	gctx.Request = httptest.NewRequest("DELETE", "/routes/"+string(routeID), nil)
+	gctx.AddParam("routeID", string(routeID))
+	gctx.Set(api.UserCtxKey, api.RequestContext{
+		UserID: userID,
+	})
+
+	API.DeleteRoute(gctx)
+
+	if gctx.Writer.Status() != 409 {
+		t.Errorf("expected status 409, got %d", gctx.Writer.Status())
+	}
+}
@@ -0,0 +1,154 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/AustinBayley/activity_tracker_api/pkg/challenges"
+	"github.com/AustinBayley/activity_tracker_api/pkg/progress"
+	"github.com/AustinBayley/activity_tracker_api/pkg/service"
+	"github.com/AustinBayley/activity_tracker_api/pkg/svg"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// svgCacheControl lets embedded images be cached briefly, as progress changes slowly.
+	svgCacheControl = "public, max-age=300"
+	maxMapSize      = 2000
+)
+
+type MapOptions struct {
+	Width  int `form:"width,default=600"`
+	Height int `form:"height,default=400"`
+}
+
+func writeSVG(req *gin.Context, doc []byte) {
+	req.Header("Cache-Control", svgCacheControl)
+	req.Data(http.StatusOK, svg.ContentType, doc)
+}
+
+// getMemberProgress gets a member's progress, responding with an error if it can't.
+func (a *API) getMemberProgress(req *gin.Context, challengeID service.ID) (*progress.Record, bool) {
+	uID := req.Param("userID")
+	if uID == "" {
+		req.JSON(http.StatusBadRequest, ErrorResponse{
+			Cause: "user ID not supplied",
+		})
+		return nil, false
+	}
+
+	record, err := a.progress.Get(req, challengeID, service.ID(uID))
+	if err != nil {
+		if errors.Is(err, progress.ErrNotFound) {
+			req.JSON(http.StatusNotFound, ErrorResponse{
+				Cause: NotFound,
+			})
+			return nil, false
+		}
+
+		log.Error().
+			Err(err).
+			Str("userID", uID).
+			Str("challengeID", string(challengeID)).
+			Msg("error getting challenge progress")
+
+		req.JSON(http.StatusInternalServerError, ErrorResponse{
+			Cause: InternalServer,
+		})
+		return nil, false
+	}
+
+	return record, true
+}
+
+// GetProgressMap renders the route of a challenge as an SVG, with the part a member has covered
+// highlighted and a marker at their position.
+func (a *API) GetProgressMap(req *gin.Context) {
+	opts := MapOptions{}
+	if err := req.BindQuery(&opts); err != nil {
+		log.Error().
+			Err(err).
+			Msg("error binding query parameters")
+	}
+
+	if opts.Width < svg.MinMapWidth || opts.Height < svg.MinMapHeight || opts.Width > maxMapSize || opts.Height > maxMapSize {
+		req.JSON(http.StatusBadRequest, ErrorResponse{
+			Cause: fmt.Sprintf("width must be between %d and %d, and height between %d and %d", svg.MinMapWidth, maxMapSize, svg.MinMapHeight, maxMapSize),
+		})
+		return
+	}
+
+	challenge, target, ok := a.getRouteChallenge(req)
+	if !ok {
+		return
+	}
+
+	record, ok := a.getMemberProgress(req, challenge.ID)
+	if !ok {
+		return
+	}
+
+	p, err := routeProgress(record.Progress)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Str("challengeID", challenge.ID.ConvertID()).
+			Str("userID", string(record.User)).
+			Msg("error reading route progress")
+
+		req.JSON(http.StatusInternalServerError, ErrorResponse{
+			Cause: InternalServer,
+		})
+		return
+	}
+
+	doc := svg.Map(target.Route.Waypoints, target.Route.Index(), svg.MapOptions{
+		Width:    opts.Width,
+		Height:   opts.Height,
+		Title:    fmt.Sprintf("%s: %.0f%%", challenge.Name, p.Percent),
+		Covered:  p.DistanceCovered,
+		Position: &p.Location.LatLng,
+	})
+
+	writeSVG(req, doc)
+}
+
+// GetProgressBadge renders a member's progress towards any challenge as a compact SVG progress bar.
+func (a *API) GetProgressBadge(req *gin.Context) {
+	id := req.Param("id")
+	if id == "" {
+		req.JSON(http.StatusBadRequest, ErrorResponse{
+			Cause: "challenge ID not supplied",
+		})
+		return
+	}
+
+	challenge := challenges.Detail{}
+	if err := a.challenges.Get(req, service.ID(id), &challenge); err != nil {
+		log.Error().
+			Err(err).
+			Str("challengeID", id).
+			Msg("error getting challenge")
+
+		if errors.Is(err, challenges.ErrNotFound) {
+			req.JSON(http.StatusNotFound, ErrorResponse{
+				Cause: NotFound,
+			})
+			return
+		}
+
+		req.JSON(http.StatusInternalServerError, ErrorResponse{
+			Cause: InternalServer,
+		})
+		return
+	}
+
+	record, ok := a.getMemberProgress(req, challenge.ID)
+	if !ok {
+		return
+	}
+
+	writeSVG(req, svg.Badge(challenge.Name, record.Progress.Percentage()))
+}
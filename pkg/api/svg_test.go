@@ -0,0 +1,65 @@
+package api_test
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AustinBayley/activity_tracker_api/pkg/activities"
+	"github.com/AustinBayley/activity_tracker_api/pkg/service"
+	"github.com/AustinBayley/activity_tracker_api/pkg/svg"
+	"github.com/gin-gonic/gin"
+)
+
+func TestGetProgressSVG(t *testing.T) {
+	ctx := context.Background()
+	userID := service.ID("svg_user")
+	cID := createRouteChallenge(t, []service.ID{userID})
+
+	activity := activities.Activity{
+		Type:   activities.Running,
+		UserID: userID,
+		Value:  5,
+		Start:  time.Now().Add(-4 * time.Hour),
+		End:    time.Now().Add(-3 * time.Hour),
+	}
+	if _, err := Activities.Create(ctx, &activity); err != nil {
+		t.Fatalf("failed to create test activity: %v", err)
+	}
+
+	tests := []struct {
+		path    string
+		handler gin.HandlerFunc
+		expect  string
+	}{
+		{"progress.svg", API.GetProgressMap, "<polyline"},
+		{"badge.svg", API.GetProgressBadge, "GeoJSON Challenge"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/challenges/"+string(cID)+"/members/"+string(userID)+"/"+tt.path, nil)
+			recorder := httptest.NewRecorder()
+			gctx := gin.CreateTestContextOnly(recorder, API.Engine)
+			gctx.AddParam("id", string(cID))
+			gctx.AddParam("userID", string(userID))
+			gctx.Request = req
+
+			tt.handler(gctx)
+
+			if gctx.Writer.Status() != 200 {
+				t.Fatalf("expected status 200, got %d", gctx.Writer.Status())
+			}
+
+			if ct := recorder.Header().Get("Content-Type"); ct != svg.ContentType {
+				t.Errorf("expected content type %s, got %s", svg.ContentType, ct)
+			}
+
+			if body := recorder.Body.String(); !strings.HasPrefix(body, "<svg") || !strings.Contains(body, tt.expect) {
+				t.Errorf("expected svg containing %q, got %s", tt.expect, body)
+			}
+		})
+	}
+}
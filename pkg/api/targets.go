@@ -0,0 +1,33 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/AustinBayley/activity_tracker_api/pkg/targets"
+	"github.com/gin-gonic/gin"
+)
+
+type TargetTypeResponse struct {
+	Type   targets.TargetType     `json:"type"`
+	Schema map[string]interface{} `json:"schema"`
+}
+
+// GetTargetTypes lists the registered target types, each with a JSON Schema describing its configuration.
+func (a *API) GetTargetTypes(req *gin.Context) {
+	types := targets.Types()
+
+	res := make([]TargetTypeResponse, 0, len(types))
+	for _, t := range types {
+		schema, ok := targets.Schema(t)
+		if !ok {
+			continue
+		}
+
+		res = append(res, TargetTypeResponse{
+			Type:   t,
+			Schema: schema,
+		})
+	}
+
+	req.JSON(http.StatusOK, res)
+}
@@ -0,0 +1,43 @@
+package api_test
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/AustinBayley/activity_tracker_api/pkg/api"
+	"github.com/AustinBayley/activity_tracker_api/pkg/targets"
+	"github.com/gin-gonic/gin"
+)
+
+func TestGetTargetTypes(t *testing.T) {
+	req := httptest.NewRequest("GET", "/targets/types", nil)
+	recorder := httptest.NewRecorder()
+	ctx := gin.CreateTestContextOnly(recorder, API.Engine)
+	ctx.Request = req
+
+	API.GetTargetTypes(ctx)
+
+	if ctx.Writer.Status() != 200 {
+		t.Fatalf("expected status 200, got %d", ctx.Writer.Status())
+	}
+
+	var types []api.TargetTypeResponse
+	if err := json.NewDecoder(recorder.Body).Decode(&types); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	found := false
+	for _, tt := range types {
+		if tt.Type == targets.RouteMovingTargetType {
+			found = true
+			if tt.Schema["type"] != "object" {
+				t.Errorf("expected object schema, got %v", tt.Schema["type"])
+			}
+		}
+	}
+
+	if !found {
+		t.Errorf("expected %s to be listed", targets.RouteMovingTargetType)
+	}
+}
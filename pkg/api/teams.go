@@ -0,0 +1,440 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/AustinBayley/activity_tracker_api/pkg/challenges"
+	"github.com/AustinBayley/activity_tracker_api/pkg/progress"
+	"github.com/AustinBayley/activity_tracker_api/pkg/service"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+type TeamRequest struct {
+	Name string `json:"name"`
+}
+
+type TeamResponse struct {
+	challenges.Team `json:",inline"`
+	Members         []service.ID `json:"members"`
+}
+
+// GetTeams lists the teams of a challenge along with their members.
+func (a *API) GetTeams(req *gin.Context) {
+	id := req.Param("id")
+	if id == "" {
+		req.JSON(http.StatusBadRequest, ErrorResponse{
+			Cause: "challenge ID not supplied",
+		})
+		return
+	}
+
+	challengeID := service.ID(id)
+
+	challenge := challenges.Detail{}
+	if err := a.challenges.Get(req, challengeID, &challenge); err != nil {
+		log.Error().
+			Err(err).
+			Str("challengeID", id).
+			Msg("error getting challenge")
+
+		if errors.Is(err, challenges.ErrNotFound) {
+			req.JSON(http.StatusNotFound, ErrorResponse{
+				Cause: NotFound,
+			})
+			return
+		}
+
+		req.JSON(http.StatusInternalServerError, ErrorResponse{
+			Cause: InternalServer,
+		})
+		return
+	}
+
+	teamOpts := challenges.NewTeamListOptions()
+	teamOpts.SetChallenge(challengeID)
+
+	teams := []challenges.Team{}
+	if err := a.challenges.ListTeams(req, teamOpts, &teams); err != nil {
+		log.Error().
+			Err(err).
+			Str("challengeID", id).
+			Msg("error listing teams")
+
+		req.JSON(http.StatusInternalServerError, ErrorResponse{
+			Cause: InternalServer,
+		})
+		return
+	}
+
+	memOpts := challenges.NewMembershipListOptions()
+	memOpts.SetChallenge(challengeID)
+
+	mems := []challenges.Membership{}
+	if err := a.challenges.ListMemberships(req, memOpts, &mems); err != nil {
+		log.Error().
+			Err(err).
+			Str("challengeID", id).
+			Msg("error listing memberships")
+
+		req.JSON(http.StatusInternalServerError, ErrorResponse{
+			Cause: InternalServer,
+		})
+		return
+	}
+
+	members := map[service.ID][]service.ID{}
+	for _, m := range mems {
+		if m.Team != nil {
+			members[*m.Team] = append(members[*m.Team], m.User)
+		}
+	}
+
+	res := make([]TeamResponse, 0, len(teams))
+	for _, t := range teams {
+		ms := members[t.ID]
+		if ms == nil {
+			ms = []service.ID{}
+		}
+
+		res = append(res, TeamResponse{
+			Team:    t,
+			Members: ms,
+		})
+	}
+
+	req.JSON(http.StatusOK, res)
+}
+
+// PostTeam adds a team to a challenge. Only the challenge creator or an admin can add teams.
+func (a *API) PostTeam(req *gin.Context) {
+	id := req.Param("id")
+	if id == "" {
+		req.JSON(http.StatusBadRequest, ErrorResponse{
+			Cause: "challenge ID not supplied",
+		})
+		return
+	}
+
+	body := TeamRequest{}
+	if err := req.BindJSON(&body); err != nil {
+		log.Error().
+			Err(err).
+			Msg("error binding JSON to team")
+
+		req.JSON(http.StatusBadRequest, ErrorResponse{
+			Cause: "invalid request body",
+		})
+		return
+	}
+
+	challenge := challenges.Detail{}
+	if err := a.challenges.Get(req, service.ID(id), &challenge); err != nil {
+		log.Error().
+			Err(err).
+			Str("challengeID", id).
+			Msg("error getting challenge")
+
+		if errors.Is(err, challenges.ErrNotFound) {
+			req.JSON(http.StatusNotFound, ErrorResponse{
+				Cause: NotFound,
+			})
+			return
+		}
+
+		req.JSON(http.StatusInternalServerError, ErrorResponse{
+			Cause: InternalServer,
+		})
+		return
+	}
+
+	actor, ok := GetActorContext(req)
+	if !ok {
+		log.Error().
+			Str("ID", challenge.ID.ConvertID()).
+			Msg("failed to get actor from context")
+
+		req.JSON(http.StatusUnauthorized, ErrorResponse{
+			Cause: Unauthorised,
+		})
+		return
+	}
+
+	if challenge.CreatedBy != actor.UserID && !actor.Admin {
+		log.Error().
+			Str("ID", challenge.ID.ConvertID()).
+			Msg("actor is not allowed to add challenge teams")
+
+		req.JSON(http.StatusForbidden, ErrorResponse{
+			Cause: "not allowed to add challenge teams",
+		})
+		return
+	}
+
+	team := challenges.Team{
+		Challenge: challenge.ID,
+		Name:      body.Name,
+	}
+
+	tID, err := a.challenges.CreateTeam(req, &team)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Str("challengeID", id).
+			Msg("error creating team")
+
+		switch {
+		case errors.Is(err, challenges.ErrValidation):
+			req.JSON(http.StatusUnprocessableEntity, ErrorResponse{
+				Cause: Validation,
+			})
+			return
+		case errors.Is(err, challenges.ErrAlreadyExists):
+			req.JSON(http.StatusConflict, ErrorResponse{
+				Cause: "team already exists",
+			})
+			return
+		}
+
+		req.JSON(http.StatusInternalServerError, ErrorResponse{
+			Cause: InternalServer,
+		})
+		return
+	}
+	team.ID = tID
+
+	req.JSON(http.StatusCreated, TeamResponse{
+		Team:    team,
+		Members: []service.ID{},
+	})
+}
+
+// SetTeamMembership moves a member of a challenge into a team, or removes them from it. Members
+// can only be in one team at a time, so joining a team leaves any other.
+func (a *API) SetTeamMembership(join bool) gin.HandlerFunc {
+	return func(req *gin.Context) {
+		userID := req.Param("userID")
+		if userID == "" {
+			req.JSON(http.StatusBadRequest, ErrorResponse{
+				Cause: "user ID not supplied",
+			})
+			return
+		}
+
+		actor, ok := GetActorContext(req)
+		if !ok {
+			log.Error().
+				Msg("failed to get actor from context")
+
+			req.JSON(http.StatusUnauthorized, ErrorResponse{
+				Cause: Unauthorised,
+			})
+			return
+		}
+
+		if service.ID(userID) != actor.UserID && !actor.Admin {
+			log.Error().
+				Str("ID", userID).
+				Msg("actor is not allowed to update user teams")
+
+			req.JSON(http.StatusForbidden, ErrorResponse{
+				Cause: "not allowed to update user teams",
+			})
+			return
+		}
+
+		challengeID := req.Param("id")
+		if challengeID == "" {
+			req.JSON(http.StatusBadRequest, ErrorResponse{
+				Cause: "challenge ID not supplied",
+			})
+			return
+		}
+
+		teamID := service.ID(req.Param("teamID"))
+		if teamID == "" {
+			req.JSON(http.StatusBadRequest, ErrorResponse{
+				Cause: "team ID not supplied",
+			})
+			return
+		}
+
+		if err := a.challenges.GetTeam(req, service.ID(challengeID), teamID, &challenges.Team{}); err != nil {
+			log.Error().
+				Err(err).
+				Str("challengeID", challengeID).
+				Str("teamID", string(teamID)).
+				Msg("error getting team")
+
+			if errors.Is(err, challenges.ErrNotFound) {
+				req.JSON(http.StatusNotFound, ErrorResponse{
+					Cause: NotFound,
+				})
+				return
+			}
+
+			req.JSON(http.StatusInternalServerError, ErrorResponse{
+				Cause: InternalServer,
+			})
+			return
+		}
+
+		op := challenges.SetTeamOperation{
+			Challenge: service.ID(challengeID),
+			User:      service.ID(userID),
+		}
+
+		if join {
+			op.Team = &teamID
+		} else {
+			membership, err := a.challenges.GetMembership(req, service.ID(challengeID), service.ID(userID))
+			if err != nil && !errors.Is(err, challenges.ErrNotFound) {
+				log.Error().
+					Err(err).
+					Str("userID", userID).
+					Str("challengeID", challengeID).
+					Msg("error getting challenge membership")
+
+				req.JSON(http.StatusInternalServerError, ErrorResponse{
+					Cause: InternalServer,
+				})
+				return
+			}
+
+			if membership == nil || membership.Team == nil || *membership.Team != teamID {
+				req.JSON(http.StatusNotFound, ErrorResponse{
+					Cause: NotFound,
+				})
+				return
+			}
+		}
+
+		if err := a.challenges.Update(req, op); err != nil {
+			log.Error().
+				Err(err).
+				Str("userID", userID).
+				Str("challengeID", challengeID).
+				Str("teamID", string(teamID)).
+				Bool("join", join).
+				Msg("error setting team membership")
+
+			if errors.Is(err, challenges.ErrNotFound) {
+				req.JSON(http.StatusNotFound, ErrorResponse{
+					Cause: NotFound,
+				})
+				return
+			}
+
+			req.JSON(http.StatusInternalServerError, ErrorResponse{
+				Cause: InternalServer,
+			})
+			return
+		}
+
+		req.Status(http.StatusNoContent)
+	}
+}
+
+// GetTeamProgress returns the pooled progress of a team's members towards a challenge.
+func (a *API) GetTeamProgress(req *gin.Context) {
+	id := req.Param("id")
+	if id == "" {
+		req.JSON(http.StatusBadRequest, ErrorResponse{
+			Cause: "challenge ID not supplied",
+		})
+		return
+	}
+
+	teamID := req.Param("teamID")
+	if teamID == "" {
+		req.JSON(http.StatusBadRequest, ErrorResponse{
+			Cause: "team ID not supplied",
+		})
+		return
+	}
+
+	record, err := a.progress.GetTeam(req, service.ID(id), service.ID(teamID))
+	if err != nil {
+		if errors.Is(err, progress.ErrNotFound) {
+			req.JSON(http.StatusNotFound, ErrorResponse{
+				Cause: NotFound,
+			})
+			return
+		}
+
+		log.Error().
+			Err(err).
+			Str("teamID", teamID).
+			Str("challengeID", id).
+			Msg("error getting team progress")
+
+		req.JSON(http.StatusInternalServerError, ErrorResponse{
+			Cause: InternalServer,
+		})
+		return
+	}
+
+	req.JSON(http.StatusOK, record)
+}
+
+type TeamLeaderboardEntry struct {
+	Rank int `json:"rank"`
+	progress.TeamRecord
+}
+
+// GetTeamLeaderboard ranks the teams of a challenge by their pooled progress. Teams with the
+// same progress are ranked by who reached it first.
+func (a *API) GetTeamLeaderboard(req *gin.Context) {
+	id := req.Param("id")
+	if id == "" {
+		req.JSON(http.StatusBadRequest, ErrorResponse{
+			Cause: "challenge ID not supplied",
+		})
+		return
+	}
+
+	rawOpts := ListOptions{}
+	if err := req.BindQuery(&rawOpts); err != nil {
+		log.Error().
+			Err(err).
+			Msg("error binding query parameters")
+	}
+
+	records, err := a.progress.ListTeams(req, service.ID(id))
+	if err != nil {
+		if errors.Is(err, progress.ErrNotFound) {
+			req.JSON(http.StatusNotFound, ErrorResponse{
+				Cause: NotFound,
+			})
+			return
+		}
+
+		log.Error().
+			Err(err).
+			Str("challengeID", id).
+			Msg("error listing team progress")
+
+		req.JSON(http.StatusInternalServerError, ErrorResponse{
+			Cause: InternalServer,
+		})
+		return
+	}
+
+	if rawOpts.Max <= 0 {
+		rawOpts.Max = 10
+	}
+
+	skip := int(max(rawOpts.Page-1, 0) * rawOpts.Max)
+	end := min(skip+int(rawOpts.Max), len(records))
+
+	entries := []TeamLeaderboardEntry{}
+	for i := skip; i < end; i++ {
+		entries = append(entries, TeamLeaderboardEntry{
+			Rank:       i + 1,
+			TeamRecord: records[i],
+		})
+	}
+
+	req.JSON(http.StatusOK, entries)
+}
@@ -0,0 +1,128 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/AustinBayley/activity_tracker_api/pkg/activities"
+	"github.com/AustinBayley/activity_tracker_api/pkg/challenges"
+	"github.com/AustinBayley/activity_tracker_api/pkg/service"
+	"github.com/AustinBayley/activity_tracker_api/pkg/targets"
+	"github.com/gin-gonic/gin"
+)
+
+func TestGetTeamLeaderboard(t *testing.T) {
+	ctx := context.Background()
+	members := []service.ID{"team_a1", "team_a2", "team_b1"}
+
+	challenge := challenges.Challenge{
+		Detail: challenges.Detail{
+			BaseDetail: challenges.BaseDetail{
+				Name:        "Team Challenge",
+				Description: "A test challenge",
+				CreatedBy:   members[0],
+				StartDate:   time.Now().Add(-20 * time.Hour),
+				EndDate:     time.Now().Add(20 * time.Hour),
+			},
+			Target: &targets.DistanceTarget{
+				BaseTarget: targets.BaseTarget{
+					TargetType: targets.DistanceTargetType,
+				},
+				Distance: 20,
+			},
+		},
+		Members: members,
+	}
+
+	cID, err := Challenges.Create(ctx, &challenge)
+	if err != nil {
+		t.Fatalf("failed to create test challenge: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = Challenges.Delete(ctx, cID)
+		for _, m := range members {
+			_ = Activities.Delete(ctx, activities.ActivityDeleteOpts{User: &m})
+		}
+	})
+
+	teamIDs := map[string]service.ID{}
+	for _, name := range []string{"A", "B"} {
+		id, err := Challenges.CreateTeam(ctx, &challenges.Team{Challenge: cID, Name: name})
+		if err != nil {
+			t.Fatalf("failed to create test team: %v", err)
+		}
+		teamIDs[name] = id
+	}
+
+	joins := map[service.ID]string{"team_a1": "A", "team_a2": "A", "team_b1": "B"}
+	for user, team := range joins {
+		teamID := teamIDs[team]
+		op := challenges.SetTeamOperation{Challenge: cID, User: user, Team: &teamID}
+		if err := Challenges.Update(ctx, op); err != nil {
+			t.Fatalf("failed to join team: %v", err)
+		}
+	}
+
+	// Neither member of team A completes the challenge alone, but together they beat team B
+	logs := []struct {
+		user  service.ID
+		value float64
+	}{
+		{"team_a1", 12},
+		{"team_a2", 9},
+		{"team_b1", 15},
+	}
+	for _, l := range logs {
+		activity := activities.Activity{
+			Type:   activities.Running,
+			UserID: l.user,
+			Value:  l.value,
+			Start:  time.Now().Add(-4 * time.Hour),
+			End:    time.Now().Add(-3 * time.Hour),
+		}
+		if _, err := Activities.Create(ctx, &activity); err != nil {
+			t.Fatalf("failed to create test activity: %v", err)
+		}
+	}
+
+	req := httptest.NewRequest("GET", "/challenges/"+string(cID)+"/leaderboard/teams", nil)
+	recorder := httptest.NewRecorder()
+	gctx := gin.CreateTestContextOnly(recorder, API.Engine)
+	gctx.AddParam("id", string(cID))
+	gctx.Request = req
+
+	API.GetTeamLeaderboard(gctx)
+
+	if gctx.Writer.Status() != 200 {
+		t.Fatalf("expected status 200, got %d", gctx.Writer.Status())
+	}
+
+	var entries []struct {
+		Rank int `json:"rank"`
+		Team struct {
+			Name string `json:"name"`
+		} `json:"team"`
+		Members  []service.ID `json:"members"`
+		Progress struct {
+			Percent float64 `json:"percent"`
+		} `json:"progress"`
+	}
+	if err := json.NewDecoder(recorder.Body).Decode(&entries); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+
+	if entries[0].Team.Name != "A" || len(entries[0].Members) != 2 || entries[0].Progress.Percent != 100 {
+		t.Errorf("expected team A with 2 members at 100%%, got %+v", entries[0])
+	}
+
+	if entries[1].Team.Name != "B" || entries[1].Progress.Percent != 75 {
+		t.Errorf("expected team B at 75%%, got %+v", entries[1])
+	}
+}
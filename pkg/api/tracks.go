@@ -0,0 +1,196 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/AustinBayley/activity_tracker_api/pkg/activities"
+	"github.com/AustinBayley/activity_tracker_api/pkg/locations"
+	"github.com/AustinBayley/activity_tracker_api/pkg/service"
+	"github.com/AustinBayley/activity_tracker_api/pkg/tracks"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// maxTrackSize is the largest request body of track points accepted, with room for
+	// tracks.MaxPoints points.
+	maxTrackSize = 32 << 20
+)
+
+// getTrackActivity gets the activity a track belongs to, responding with an error if it can't.
+func (a *API) getTrackActivity(req *gin.Context) (*activities.Activity, bool) {
+	id := req.Param("activityID")
+	if id == "" {
+		req.JSON(http.StatusBadRequest, ErrorResponse{
+			Cause: "activity ID not supplied",
+		})
+		return nil, false
+	}
+
+	activity := activities.Activity{}
+	if err := a.activities.Get(req, service.ID(id), &activity); err != nil {
+		log.Error().
+			Err(err).
+			Str("activityID", id).
+			Msg("error getting activity")
+
+		if errors.Is(err, activities.ErrNotFound) {
+			req.JSON(http.StatusNotFound, ErrorResponse{
+				Cause: NotFound,
+			})
+			return nil, false
+		}
+
+		req.JSON(http.StatusInternalServerError, ErrorResponse{
+			Cause: InternalServer,
+		})
+		return nil, false
+	}
+
+	return &activity, true
+}
+
+// GetActivityTrack returns the recorded track of an activity as a LineString feature, with the
+// time of each point and the metrics derived from the track as properties. Tracks show where users
+// go, so only the user who recorded one and admins can get it.
+func (a *API) GetActivityTrack(req *gin.Context) {
+	activity, ok := a.getTrackActivity(req)
+	if !ok {
+		return
+	}
+
+	actor, ok := GetActorContext(req)
+	if !ok {
+		log.Error().
+			Msg("failed to get actor from context")
+
+		req.JSON(http.StatusUnauthorized, ErrorResponse{
+			Cause: Unauthorised,
+		})
+		return
+	}
+
+	if activity.UserID != actor.UserID && !actor.Admin {
+		log.Error().
+			Str("activityID", activity.ID.ConvertID()).
+			Msg("actor is not allowed to get activity track")
+
+		req.JSON(http.StatusForbidden, ErrorResponse{
+			Cause: "not allowed to get activity track",
+		})
+		return
+	}
+
+	track := tracks.Track{}
+	if err := a.tracks.Get(req, activity.ID, &track); err != nil {
+		log.Error().
+			Err(err).
+			Str("activityID", activity.ID.ConvertID()).
+			Msg("error getting activity track")
+
+		if errors.Is(err, tracks.ErrNotFound) {
+			req.JSON(http.StatusNotFound, ErrorResponse{
+				Cause: NotFound,
+			})
+			return
+		}
+
+		req.JSON(http.StatusInternalServerError, ErrorResponse{
+			Cause: InternalServer,
+		})
+		return
+	}
+
+	writeGeoJSON(req, locations.NewFeatureCollection(track.Feature()))
+}
+
+// PutActivityTrack replaces the track of an activity with the points in the body. The activity
+// itself is left as it is.
+func (a *API) PutActivityTrack(req *gin.Context) {
+	activity, ok := a.getTrackActivity(req)
+	if !ok {
+		return
+	}
+
+	actor, ok := GetActorContext(req)
+	if !ok {
+		log.Error().
+			Msg("failed to get actor from context")
+
+		req.JSON(http.StatusUnauthorized, ErrorResponse{
+			Cause: Unauthorised,
+		})
+		return
+	}
+
+	if activity.UserID != actor.UserID && !actor.Admin {
+		log.Error().
+			Str("activityID", activity.ID.ConvertID()).
+			Msg("actor is not allowed to update activity track")
+
+		req.JSON(http.StatusForbidden, ErrorResponse{
+			Cause: "not allowed to update activity track",
+		})
+		return
+	}
+
+	req.Request.Body = http.MaxBytesReader(req.Writer, req.Request.Body, maxTrackSize)
+
+	points := tracks.Points{}
+	if err := req.ShouldBindJSON(&points); err != nil {
+		if tooLarge(err) {
+			respondTooLarge(req, maxTrackSize)
+			return
+		}
+
+		req.JSON(http.StatusBadRequest, ErrorResponse{
+			Cause: "invalid track points",
+		})
+		return
+	}
+
+	if len(points) == 0 || len(points) > tracks.MaxPoints {
+		req.JSON(http.StatusUnprocessableEntity, ErrorResponse{
+			Cause: fmt.Sprintf("track must have between 1 and %d positions", tracks.MaxPoints),
+		})
+		return
+	}
+
+	for _, p := range points {
+		if !p.LatLng.Valid() {
+			req.JSON(http.StatusUnprocessableEntity, ErrorResponse{
+				Cause: "lat must be between -90 and 90 and lng between -180 and 180",
+			})
+			return
+		}
+	}
+
+	track := tracks.Track{
+		Activity: activity.ID,
+		User:     activity.UserID,
+		Points:   points,
+	}
+
+	if err := a.tracks.Replace(req, &track); err != nil {
+		log.Error().
+			Err(err).
+			Str("activityID", activity.ID.ConvertID()).
+			Msg("error replacing activity track")
+
+		if errors.Is(err, tracks.ErrValidation) {
+			req.JSON(http.StatusUnprocessableEntity, ErrorResponse{
+				Cause: Validation,
+			})
+			return
+		}
+
+		req.JSON(http.StatusInternalServerError, ErrorResponse{
+			Cause: InternalServer,
+		})
+		return
+	}
+
+	req.JSON(http.StatusOK, track)
+}
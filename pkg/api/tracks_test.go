@@ -0,0 +1,142 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AustinBayley/activity_tracker_api/pkg/api"
+	"github.com/AustinBayley/activity_tracker_api/pkg/locations"
+	"github.com/AustinBayley/activity_tracker_api/pkg/service"
+	"github.com/AustinBayley/activity_tracker_api/pkg/tracks"
+	"github.com/gin-gonic/gin"
+)
+
+func TestActivityTrack(t *testing.T) {
+	ctx := context.Background()
+
+	activity, cleanup, err := CreateTestActivity(ctx, "Track Activity")
+	if err != nil {
+		t.Fatalf("failed to create test activity: %v", err)
+	}
+	t.Cleanup(cleanup)
+	t.Cleanup(func() {
+		_ = Tracks.Delete(ctx, tracks.TrackDeleteOpts{Activity: &activity.ID})
+	})
+
+	elevation := 10.0
+	points := tracks.Points{
+		{Time: activity.Start, LatLng: locations.LatLng{Lat: 51.45, Lng: -2.60}, Elevation: &elevation},
+		{Time: activity.Start.Add(10 * time.Minute), LatLng: locations.LatLng{Lat: 51.45, Lng: -2.58}, Elevation: &elevation},
+	}
+
+	bb, err := json.Marshal(points)
+	if err != nil {
+		t.Fatalf("failed to marshal points: %v", err)
+	}
+
+	for _, tt := range []struct {
+		actor  service.ID
+		status int
+	}{
+		{"someone_else", 403},
+		{"test_user", 200},
+	} {
+		req := httptest.NewRequest("PUT", "/activities/"+string(activity.ID)+"/track", strings.NewReader(string(bb)))
+		req.Header.Set("Content-Type", "application/json")
+
+		recorder := httptest.NewRecorder()
+		gctx := gin.CreateTestContextOnly(recorder, API.Engine)
+		gctx.Request = req
+		gctx.AddParam("activityID", string(activity.ID))
+		gctx.Set(api.UserCtxKey, api.RequestContext{
+			UserID: tt.actor,
+		})
+
+		API.PutActivityTrack(gctx)
+
+		if gctx.Writer.Status() != tt.status {
+			t.Fatalf("expected status %d for %s, got %d", tt.status, tt.actor, gctx.Writer.Status())
+		}
+	}
+
+	// A body too large to be a track is rejected, leaving the stored track in place
+	req := httptest.NewRequest("PUT", "/activities/"+string(activity.ID)+"/track", strings.NewReader("["+strings.Repeat(" ", 33<<20)+"]"))
+	req.Header.Set("Content-Type", "application/json")
+
+	gctx := gin.CreateTestContextOnly(httptest.NewRecorder(), API.Engine)
+	gctx.Request = req
+	gctx.AddParam("activityID", string(activity.ID))
+	gctx.Set(api.UserCtxKey, api.RequestContext{
+		UserID: "test_user",
+	})
+
+	API.PutActivityTrack(gctx)
+
+	if gctx.Writer.Status() != 413 {
+		t.Fatalf("expected status 413, got %d", gctx.Writer.Status())
+	}
+
+	// Only the user who recorded the track can get it
+	var recorder *httptest.ResponseRecorder
+	for _, tt := range []struct {
+		actor  service.ID
+		status int
+	}{
+		{"", 401},
+		{"someone_else", 403},
+		{"test_user", 200},
+	} {
+		recorder = httptest.NewRecorder()
+		gctx = gin.CreateTestContextOnly(recorder, API.Engine)
+		gctx.Request = httptest.NewRequest("GET", "/activities/"+string(activity.ID)+"/track", nil)
+		gctx.AddParam("activityID", string(activity.ID))
+		if tt.actor != "" {
+			gctx.Set(api.UserCtxKey, api.RequestContext{
+				UserID: tt.actor,
+			})
+		}
+
+		API.GetActivityTrack(gctx)
+
+		if gctx.Writer.Status() != tt.status {
+			t.Fatalf("expected status %d for %q, got %d", tt.status, tt.actor, gctx.Writer.Status())
+		}
+	}
+
+	if ct := recorder.Header().Get("Content-Type"); ct != locations.GeoJSONContentType {
+		t.Errorf("expected content type %s, got %s", locations.GeoJSONContentType, ct)
+	}
+
+	fc := struct {
+		Features []struct {
+			Geometry struct {
+				Type        string      `json:"type"`
+				Coordinates [][]float64 `json:"coordinates"`
+			} `json:"geometry"`
+			Properties struct {
+				Metrics tracks.Metrics `json:"metrics"`
+			} `json:"properties"`
+		} `json:"features"`
+	}{}
+	if err := json.NewDecoder(recorder.Body).Decode(&fc); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	if len(fc.Features) != 1 || fc.Features[0].Geometry.Type != "LineString" {
+		t.Fatalf("expected a single LineString feature, got %+v", fc)
+	}
+
+	// Elevations are included as a third coordinate
+	if c := fc.Features[0].Geometry.Coordinates; len(c) != 2 || len(c[0]) != 3 {
+		t.Errorf("expected 2 positions with elevations, got %v", c)
+	}
+
+	m := fc.Features[0].Properties.Metrics
+	if m.Distance <= 0 || m.MovingTime != 10 || len(m.Splits) != 2 {
+		t.Errorf("expected metrics derived from the track, got %+v", m)
+	}
+}
@@ -0,0 +1,89 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// maxFormOverhead is the room allowed around an uploaded file for the rest of a multipart form.
+	maxFormOverhead = 1 << 20
+)
+
+// readUpload reads a file uploaded either as the "file" field of a multipart form or as the
+// request body, returning its content and name if it has one. It responds with an error if the
+// file can't be read or is larger than limit bytes, without reading more of the request than that.
+func readUpload(req *gin.Context, limit int64) ([]byte, string, bool) {
+	req.Request.Body = http.MaxBytesReader(req.Writer, req.Request.Body, limit+maxFormOverhead)
+
+	var (
+		file     io.Reader = req.Request.Body
+		filename string
+	)
+	if strings.HasPrefix(req.ContentType(), "multipart/") {
+		header, err := req.FormFile("file")
+		if err != nil {
+			if tooLarge(err) {
+				respondTooLarge(req, limit)
+				return nil, "", false
+			}
+
+			req.JSON(http.StatusBadRequest, ErrorResponse{
+				Cause: "file not supplied",
+			})
+			return nil, "", false
+		}
+
+		if header.Size > limit {
+			respondTooLarge(req, limit)
+			return nil, "", false
+		}
+
+		f, err := header.Open()
+		if err != nil {
+			req.JSON(http.StatusBadRequest, ErrorResponse{
+				Cause: "error reading file",
+			})
+			return nil, "", false
+		}
+		defer f.Close()
+		file, filename = f, header.Filename
+	}
+
+	data, err := io.ReadAll(io.LimitReader(file, limit+1))
+	if err != nil {
+		if tooLarge(err) {
+			respondTooLarge(req, limit)
+			return nil, "", false
+		}
+
+		req.JSON(http.StatusBadRequest, ErrorResponse{
+			Cause: "error reading file",
+		})
+		return nil, "", false
+	}
+
+	if int64(len(data)) > limit {
+		respondTooLarge(req, limit)
+		return nil, "", false
+	}
+
+	return data, filename, true
+}
+
+// tooLarge reports whether err is from reading more of a request than it was limited to.
+func tooLarge(err error) bool {
+	var maxBytesErr *http.MaxBytesError
+	return errors.As(err, &maxBytesErr)
+}
+
+func respondTooLarge(req *gin.Context, limit int64) {
+	req.JSON(http.StatusRequestEntityTooLarge, ErrorResponse{
+		Cause: fmt.Sprintf("file must be at most %d MB", limit>>20),
+	})
+}
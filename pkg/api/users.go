@@ -256,6 +256,8 @@ func (a *API) DeleteUser(req *gin.Context) {
 		return
 	}
 
+	a.refreshUserProgress(req, userID)
+
 	req.JSON(http.StatusNoContent, nil)
 }
 
@@ -396,6 +398,8 @@ func (a *API) SetChallengeMembership(member bool) gin.HandlerFunc {
 			return
 		}
 
+		a.refreshMemberProgress(req, service.ID(challengeID), service.ID(userID))
+
 		req.Status(http.StatusNoContent)
 	}
 }
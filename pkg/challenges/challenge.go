@@ -16,15 +16,18 @@ type Challenge struct {
 type Service struct {
 	challenges  *Details
 	memberships *Memberships
+	teams       *Teams
 }
 
 func New(
 	challenges *Details,
 	memberships *Memberships,
+	teams *Teams,
 ) *Service {
 	return &Service{
 		challenges:  challenges,
 		memberships: memberships,
+		teams:       teams,
 	}
 }
 
@@ -38,6 +41,10 @@ func (svc *Service) Setup(ctx context.Context) error {
 		return fmt.Errorf("failed to setup memberships: %w", err)
 	}
 
+	if err := svc.teams.Setup(ctx); err != nil {
+		return fmt.Errorf("failed to setup teams: %w", err)
+	}
+
 	return nil
 }
 
@@ -99,6 +106,63 @@ func (svc *Service) Get(ctx context.Context, id service.ID, challenge interface{
 	return nil
 }
 
+// GetMembership retrieves a user's membership of a challenge.
+func (svc *Service) GetMembership(ctx context.Context, challengeID service.ID, userID service.ID) (*Membership, error) {
+	opts := NewMembershipListOptions()
+	opts.SetChallenge(challengeID).
+		SetUser(userID).
+		SetLimit(1)
+
+	mems := make([]Membership, 0, 1)
+	if err := svc.memberships.List(ctx, opts, &mems); err != nil {
+		return nil, fmt.Errorf("failed to get membership: %w", err)
+	}
+
+	if len(mems) == 0 {
+		return nil, ErrNotFound
+	}
+
+	return &mems[0], nil
+}
+
+// ListMemberships retrieves memberships based on the given criteria.
+func (svc *Service) ListMemberships(ctx context.Context, opts MembershipListOptions, memberships interface{}) error {
+	if err := svc.memberships.List(ctx, opts, memberships); err != nil {
+		return fmt.Errorf("failed to list memberships: %w", err)
+	}
+	return nil
+}
+
+// CreateTeam adds a new team to a challenge and returns the team's ID.
+func (svc *Service) CreateTeam(ctx context.Context, team *Team) (service.ID, error) {
+	if err := svc.challenges.Get(ctx, team.Challenge, &Detail{}); err != nil {
+		return "", fmt.Errorf("failed to get challenge: %w", err)
+	}
+
+	id, err := svc.teams.Create(ctx, team)
+	if err != nil {
+		return "", fmt.Errorf("failed to create team: %w", err)
+	}
+
+	return id, nil
+}
+
+// GetTeam retrieves a team of a challenge by its ID.
+func (svc *Service) GetTeam(ctx context.Context, challengeID service.ID, teamID service.ID, team interface{}) error {
+	if err := svc.teams.Get(ctx, challengeID, teamID, team); err != nil {
+		return fmt.Errorf("failed to get team: %w", err)
+	}
+	return nil
+}
+
+// ListTeams retrieves teams based on the given criteria.
+func (svc *Service) ListTeams(ctx context.Context, opts TeamListOptions, teams interface{}) error {
+	if err := svc.teams.List(ctx, opts, teams); err != nil {
+		return fmt.Errorf("failed to list teams: %w", err)
+	}
+	return nil
+}
+
 type ListOptions struct {
 	Limit int64
 	Skip  int64
@@ -159,6 +223,30 @@ func (svc *Service) ListByCreator(ctx context.Context, creatorID service.ID, cha
 	return nil
 }
 
+// ListByRoute retrieves challenges whose target references a route from the library.
+func (svc *Service) ListByRoute(ctx context.Context, routeID service.ID, challenges interface{}) error {
+	opts := NewDetailListOptions()
+	opts.SetRoute(routeID)
+	if err := svc.challenges.List(ctx, opts, challenges); err != nil {
+		return fmt.Errorf("failed to list challenges by route: %w", err)
+	}
+	return nil
+}
+
+// ListNearby retrieves public challenges that haven't ended whose route passes through any of
+// the H3 cells, or that reference any of the routes from the library.
+func (svc *Service) ListNearby(ctx context.Context, cells []string, routeIDs []service.ID, challenges interface{}) error {
+	opts := NewDetailListOptions()
+	opts.SetPublic(true).
+		SetEndsAfter(time.Now()).
+		SetCells(cells).
+		SetRoutes(routeIDs)
+	if err := svc.challenges.List(ctx, opts, challenges); err != nil {
+		return fmt.Errorf("failed to list nearby challenges: %w", err)
+	}
+	return nil
+}
+
 type Operation interface {
 	Execute(ctx context.Context, details *Details, memberships *Memberships) error
 }
@@ -210,6 +298,21 @@ func (o SetMemberOperation) Execute(ctx context.Context, _ *Details, memberships
 	return nil
 }
 
+type SetTeamOperation struct {
+	Challenge service.ID
+	User      service.ID
+	// Team is the team to join, or nil to leave the current team.
+	Team *service.ID
+}
+
+// Execute sets the team of a user's membership of a challenge.
+func (o SetTeamOperation) Execute(ctx context.Context, _ *Details, memberships *Memberships) error {
+	if err := memberships.UpdateTeam(ctx, o.Challenge, o.User, o.Team); err != nil {
+		return fmt.Errorf("failed to set team: %w", err)
+	}
+	return nil
+}
+
 // Update applies a series of operations to the challenge service, executing them in a transaction.
 func (svc *Service) Update(ctx context.Context, operations ...Operation) error {
 	session, err := svc.challenges.Database().Client().StartSession()
@@ -251,6 +354,14 @@ func (svc *Service) Delete(ctx context.Context, challengeID service.ID) error {
 			return nil, fmt.Errorf("failed to delete memberships: %w", err)
 		}
 
+		teamOpts := TeamDeleteOpts{
+			Challenge: &challengeID,
+		}
+
+		if err := svc.teams.Delete(sCtx, teamOpts); err != nil {
+			return nil, fmt.Errorf("failed to delete teams: %w", err)
+		}
+
 		return nil, nil
 	})
 
@@ -274,7 +385,7 @@ func (svc *Service) DeleteByCreator(ctx context.Context, userID service.ID) erro
 			return nil, fmt.Errorf("failed to list challenges for creator: %w", err)
 		}
 
-		// Delete memberships for each challenge
+		// Delete memberships and teams for each challenge
 		for _, challenge := range challengeList {
 			membershipOpts := MembershipDeleteOpts{
 				Challenge: &challenge.ID,
@@ -282,6 +393,13 @@ func (svc *Service) DeleteByCreator(ctx context.Context, userID service.ID) erro
 			if err := svc.memberships.Delete(sCtx, membershipOpts); err != nil {
 				return nil, fmt.Errorf("failed to delete memberships for challenge %s: %w", challenge.ID.ConvertID(), err)
 			}
+
+			teamOpts := TeamDeleteOpts{
+				Challenge: &challenge.ID,
+			}
+			if err := svc.teams.Delete(sCtx, teamOpts); err != nil {
+				return nil, fmt.Errorf("failed to delete teams for challenge %s: %w", challenge.ID.ConvertID(), err)
+			}
 		}
 
 		// Delete all challenges created by this user
@@ -23,10 +23,29 @@ type BaseDetail struct {
 	EndDate     time.Time  `json:"end_date" bson:"endDate" validate:"required"`
 	Public      bool       `json:"public" bson:"public"`
 	InviteOnly  bool       `json:"invite_only" bson:"inviteOnly"`
+	// ExcludeBeforeJoin only counts a member's activities from when they joined the challenge.
+	ExcludeBeforeJoin bool `json:"exclude_before_join" bson:"excludeBeforeJoin"`
+	// Collective pools every member's activities so the whole group progresses towards the target together.
+	Collective  bool       `json:"collective" bson:"collective"`
 	CreatedBy   service.ID `json:"created_by" bson:"createdBy" validate:"required"`
 	CreatedDate time.Time  `json:"created_date" bson:"createdDate" validate:"required"`
 }
 
+// Window returns the period a member's activities count towards the challenge,
+// taking into account when they joined if ExcludeBeforeJoin is set.
+func (d BaseDetail) Window(membership Membership) targets.Window {
+	window := targets.Window{
+		Start: d.StartDate,
+		End:   d.EndDate,
+	}
+
+	if d.ExcludeBeforeJoin && membership.Created.After(window.Start) {
+		window.Start = membership.Created
+	}
+
+	return window
+}
+
 type Detail struct {
 	BaseDetail `json:",inline" bson:",inline"`
 	Target     targets.Target `json:"target" bson:"target"`
@@ -72,9 +91,60 @@ func (svc *Details) Setup(ctx context.Context) error {
 	if err := svc.Database().CreateCollection(ctx, svc.Name()); err != nil {
 		return fmt.Errorf("failed to create challenge detail collection: %w", err)
 	}
+
+	_, err := svc.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "target.route.cells", Value: 1}}},
+		{Keys: bson.D{{Key: "target.routeId", Value: 1}}},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create challenge detail indexes: %w", err)
+	}
+
+	if err := svc.indexCells(ctx); err != nil {
+		return fmt.Errorf("failed to index challenge routes: %w", err)
+	}
+
 	return nil
 }
 
+// indexCells sets the cells of inline routes saved before routes were indexed by location.
+func (svc *Details) indexCells(ctx context.Context) error {
+	cursor, err := svc.Find(ctx, bson.D{
+		{Key: "target.route.cells", Value: bson.D{{Key: "$exists", Value: false}}},
+		{Key: "target.route.waypoints.0", Value: bson.D{{Key: "$exists", Value: true}}},
+	}, options.Find().SetProjection(bson.D{{Key: "target.route.waypoints", Value: 1}}))
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		challenge := struct {
+			ID     service.ID `bson:"_id"`
+			Target struct {
+				Route targets.Route `bson:"route"`
+			} `bson:"target"`
+		}{}
+		if err := cursor.Decode(&challenge); err != nil {
+			return err
+		}
+
+		cells, err := challenge.Target.Route.Waypoints.Cells()
+		if err != nil {
+			return fmt.Errorf("challenge %s: %w", challenge.ID.ConvertID(), err)
+		}
+
+		if _, err := svc.UpdateOne(ctx,
+			bson.D{{Key: "_id", Value: challenge.ID.ConvertID()}},
+			bson.D{{Key: "$set", Value: bson.D{{Key: "target.route.cells", Value: cells}}}},
+		); err != nil {
+			return err
+		}
+	}
+
+	return cursor.Err()
+}
+
 // Create adds a new challenge to the database.
 func (svc *Details) Create(ctx context.Context, challenge *Detail) (service.ID, error) {
 	challenge.ID = service.NewID()
@@ -113,6 +183,16 @@ type DetailListOptions struct {
 	Limit     int64
 	Skip      int64
 	CreatedBy *service.ID
+	// Route limits challenges to those whose target references the route.
+	Route *service.ID
+	// Public limits challenges to those that are or aren't public.
+	Public *bool
+	// EndsAfter limits challenges to those ending after the given time.
+	EndsAfter *time.Time
+	// Cells and Routes limit challenges to those whose route passes through any of the H3 cells,
+	// or that reference any of the routes from the library.
+	Cells  []string
+	Routes []service.ID
 }
 
 func NewDetailListOptions() DetailListOptions {
@@ -134,6 +214,31 @@ func (opts *DetailListOptions) SetCreatedBy(id service.ID) *DetailListOptions {
 	return opts
 }
 
+func (opts *DetailListOptions) SetRoute(id service.ID) *DetailListOptions {
+	opts.Route = &id
+	return opts
+}
+
+func (opts *DetailListOptions) SetPublic(public bool) *DetailListOptions {
+	opts.Public = &public
+	return opts
+}
+
+func (opts *DetailListOptions) SetEndsAfter(t time.Time) *DetailListOptions {
+	opts.EndsAfter = &t
+	return opts
+}
+
+func (opts *DetailListOptions) SetCells(cells []string) *DetailListOptions {
+	opts.Cells = cells
+	return opts
+}
+
+func (opts *DetailListOptions) SetRoutes(ids []service.ID) *DetailListOptions {
+	opts.Routes = ids
+	return opts
+}
+
 // List retrieves challenges based on the given criteria.
 func (svc *Details) List(ctx context.Context, opts DetailListOptions, challenges interface{}) error {
 	options := options.Find()
@@ -150,6 +255,30 @@ func (svc *Details) List(ctx context.Context, opts DetailListOptions, challenges
 	if opts.CreatedBy != nil {
 		filter = append(filter, bson.E{Key: "createdBy", Value: opts.CreatedBy.ConvertID()})
 	}
+	if opts.Route != nil {
+		filter = append(filter, bson.E{Key: "target.routeId", Value: opts.Route.ConvertID()})
+	}
+	if opts.Public != nil {
+		filter = append(filter, bson.E{Key: "public", Value: *opts.Public})
+	}
+	if opts.EndsAfter != nil {
+		filter = append(filter, bson.E{Key: "endDate", Value: bson.D{{Key: "$gt", Value: *opts.EndsAfter}}})
+	}
+	if opts.Cells != nil || opts.Routes != nil {
+		cells := make(bson.A, 0, len(opts.Cells))
+		for _, cell := range opts.Cells {
+			cells = append(cells, cell)
+		}
+		routes := make(bson.A, 0, len(opts.Routes))
+		for _, id := range opts.Routes {
+			routes = append(routes, id.ConvertID())
+		}
+
+		filter = append(filter, bson.E{Key: "$or", Value: bson.A{
+			bson.D{{Key: "target.route.cells", Value: bson.D{{Key: "$in", Value: cells}}}},
+			bson.D{{Key: "target.routeId", Value: bson.D{{Key: "$in", Value: routes}}}},
+		}})
+	}
 
 	cursor, err := svc.Find(ctx, filter, options)
 	if err != nil {
@@ -172,7 +301,7 @@ func (svc *Details) Update(ctx context.Context, challenge Detail) error {
 	opts := options.UpdateOne().SetUpsert(true)
 	res, err := svc.UpdateOne(
 		ctx,
-		bson.D{},
+		bson.D{{Key: "_id", Value: challenge.ID.ConvertID()}},
 		bson.D{{Key: "$set", Value: challenge}},
 		opts,
 	)
@@ -14,9 +14,10 @@ import (
 )
 
 type Membership struct {
-	Challenge service.ID `json:"challenge" bson:"challenge"`
-	User      service.ID `json:"user" bson:"user"`
-	Created   time.Time  `json:"created" bson:"created"`
+	Challenge service.ID  `json:"challenge" bson:"challenge"`
+	User      service.ID  `json:"user" bson:"user"`
+	Created   time.Time   `json:"created" bson:"created"`
+	Team      *service.ID `json:"team,omitempty" bson:"team,omitempty"`
 }
 
 // Memberships wraps a MongoDB collection of challenges.
@@ -87,6 +88,7 @@ type MembershipListOptions struct {
 
 	User      *service.ID
 	Challenge *service.ID
+	Team      *service.ID
 }
 
 func NewMembershipListOptions() MembershipListOptions {
@@ -108,6 +110,16 @@ func (opts *MembershipListOptions) SetUser(id service.ID) *MembershipListOptions
 	return opts
 }
 
+func (opts *MembershipListOptions) SetChallenge(id service.ID) *MembershipListOptions {
+	opts.Challenge = &id
+	return opts
+}
+
+func (opts *MembershipListOptions) SetTeam(id service.ID) *MembershipListOptions {
+	opts.Team = &id
+	return opts
+}
+
 // List retrieves memberships based on the given criteria.
 func (svc *Memberships) List(ctx context.Context, opts MembershipListOptions, memberships interface{}) error {
 	options := options.Find()
@@ -127,6 +139,9 @@ func (svc *Memberships) List(ctx context.Context, opts MembershipListOptions, me
 	if opts.Challenge != nil {
 		filter = append(filter, bson.E{Key: "challenge", Value: opts.Challenge.ConvertID()})
 	}
+	if opts.Team != nil {
+		filter = append(filter, bson.E{Key: "team", Value: opts.Team.ConvertID()})
+	}
 
 	cursor, err := svc.Find(ctx, filter, options)
 	if err != nil {
@@ -140,6 +155,30 @@ func (svc *Memberships) List(ctx context.Context, opts MembershipListOptions, me
 	return nil
 }
 
+// UpdateTeam sets the team of a user's membership of a challenge. A nil team removes them from their team.
+func (svc *Memberships) UpdateTeam(ctx context.Context, challengeID service.ID, userID service.ID, team *service.ID) error {
+	filter := bson.D{
+		{Key: "challenge", Value: challengeID.ConvertID()},
+		{Key: "user", Value: userID.ConvertID()},
+	}
+
+	update := bson.D{{Key: "$unset", Value: bson.D{{Key: "team", Value: ""}}}}
+	if team != nil {
+		update = bson.D{{Key: "$set", Value: bson.D{{Key: "team", Value: team.ConvertID()}}}}
+	}
+
+	res, err := svc.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrUnknown, err)
+	}
+
+	if res.MatchedCount != 1 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
 type MembershipDeleteOpts struct {
 	Challenge *service.ID
 	User      *service.ID
@@ -0,0 +1,167 @@
+package challenges
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/AustinBayley/activity_tracker_api/pkg/service"
+	"github.com/AustinBayley/activity_tracker_api/pkg/validate"
+	"github.com/rs/zerolog/log"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// Team is a named group of members within a challenge whose progress is pooled.
+type Team struct {
+	ID        service.ID `json:"id" bson:"_id"`
+	Challenge service.ID `json:"challenge" bson:"challenge" validate:"required"`
+	Name      string     `json:"name" bson:"name" validate:"required"`
+	Created   time.Time  `json:"created" bson:"created"`
+}
+
+// Teams wraps a MongoDB collection of challenge teams.
+type Teams struct {
+	*mongo.Collection
+}
+
+// NewTeams creates a new Teams instance with the provided MongoDB collection.
+func NewTeams(c *mongo.Collection) *Teams {
+	return &Teams{c}
+}
+
+// Setup initializes the challenge teams collection in the database.
+func (svc *Teams) Setup(ctx context.Context) error {
+	if err := svc.Database().CreateCollection(ctx, svc.Name()); err != nil {
+		return fmt.Errorf("failed to create challenge teams collection: %w", err)
+	}
+
+	_, err := svc.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "challenge", Value: 1}, {Key: "name", Value: 1}},
+			Options: options.Index().SetUnique(true).SetName("challenge_name_unique_index"),
+		},
+	})
+
+	if err != nil {
+		log.Error().
+			Err(err).
+			Msg("failed to create unique index for challenge teams")
+	}
+
+	return nil
+}
+
+// Create adds a new team to the database.
+func (svc *Teams) Create(ctx context.Context, team *Team) (service.ID, error) {
+	team.ID = service.NewID()
+	team.Created = time.Now()
+
+	if err := validate.Struct(team); err != nil {
+		return "", fmt.Errorf("%w: %w", ErrValidation, err)
+	}
+
+	res, err := svc.InsertOne(ctx, team)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return "", ErrAlreadyExists
+		}
+		return "", fmt.Errorf("%w: %w", ErrUnknown, err)
+	}
+
+	return service.ID(res.InsertedID.(string)), nil
+}
+
+// Get retrieves a team of a challenge by its ID from the database.
+func (svc *Teams) Get(ctx context.Context, challengeID service.ID, id service.ID, team interface{}) error {
+	filter := bson.D{
+		{Key: "_id", Value: id.ConvertID()},
+		{Key: "challenge", Value: challengeID.ConvertID()},
+	}
+
+	if err := svc.FindOne(ctx, filter).Decode(team); err != nil {
+		switch {
+		case errors.Is(err, mongo.ErrNoDocuments):
+			return ErrNotFound
+		}
+		return fmt.Errorf("%w: %w", ErrUnknown, err)
+	}
+
+	return nil
+}
+
+type TeamListOptions struct {
+	Limit int64
+	Skip  int64
+
+	Challenge *service.ID
+}
+
+func NewTeamListOptions() TeamListOptions {
+	return TeamListOptions{}
+}
+
+func (opts *TeamListOptions) SetLimit(limit int64) *TeamListOptions {
+	opts.Limit = limit
+	return opts
+}
+
+func (opts *TeamListOptions) SetSkip(skip int64) *TeamListOptions {
+	opts.Skip = skip
+	return opts
+}
+
+func (opts *TeamListOptions) SetChallenge(id service.ID) *TeamListOptions {
+	opts.Challenge = &id
+	return opts
+}
+
+// List retrieves teams based on the given criteria.
+func (svc *Teams) List(ctx context.Context, opts TeamListOptions, teams interface{}) error {
+	options := options.Find()
+
+	if opts.Limit > 0 {
+		options = options.SetLimit(opts.Limit)
+	}
+
+	if opts.Skip > 0 {
+		options = options.SetSkip(opts.Skip)
+	}
+
+	filter := bson.D{}
+	if opts.Challenge != nil {
+		filter = append(filter, bson.E{Key: "challenge", Value: opts.Challenge.ConvertID()})
+	}
+
+	cursor, err := svc.Find(ctx, filter, options)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrUnknown, err)
+	}
+
+	if err := cursor.All(ctx, teams); err != nil {
+		return fmt.Errorf("%w: %w", ErrUnknown, err)
+	}
+
+	return nil
+}
+
+type TeamDeleteOpts struct {
+	Challenge *service.ID
+}
+
+// Delete removes teams based on the provided criteria.
+func (svc *Teams) Delete(ctx context.Context, opts TeamDeleteOpts) error {
+	filter := bson.D{}
+	if opts.Challenge != nil {
+		filter = append(filter, bson.E{Key: "challenge", Value: opts.Challenge.ConvertID()})
+	}
+
+	_, err := svc.DeleteMany(ctx, filter)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrUnknown, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,95 @@
+package locations
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/uber/h3-go/v4"
+)
+
+const (
+	// CellResolution is the H3 resolution routes are indexed at so they can be found by
+	// location, with cells roughly 40 km across.
+	CellResolution = 4
+	// MaxNearbyKm is the largest radius routes can be searched within.
+	MaxNearbyKm = 500
+)
+
+var (
+	ErrInvalidRadius = errors.New("invalid radius")
+	ErrInvalidLatLng = errors.New("invalid latlng")
+)
+
+// Cells returns the distinct H3 cells at CellResolution containing the waypoints, in route order.
+func (w Waypoints) Cells() ([]string, error) {
+	cells := []string{}
+	seen := map[h3.Cell]struct{}{}
+	for _, waypoint := range w {
+		cell, err := h3.LatLngToCell(h3.LatLng(waypoint.LatLng), CellResolution)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v: %w", ErrInvalidLatLng, waypoint.LatLng, err)
+		}
+
+		if _, ok := seen[cell]; ok {
+			continue
+		}
+		seen[cell] = struct{}{}
+		cells = append(cells, cell.String())
+	}
+
+	return cells, nil
+}
+
+// CellsWithin returns the H3 cells at CellResolution that may hold a point within radiusKm of
+// latlng. Cells further away are excluded, but points in the cells may still be out of range.
+func CellsWithin(latlng LatLng, radiusKm float64) ([]string, error) {
+	if !latlng.Valid() {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidLatLng, latlng)
+	}
+
+	if radiusKm <= 0 || radiusKm > MaxNearbyKm || math.IsNaN(radiusKm) {
+		return nil, fmt.Errorf("%w: must be greater than 0 and at most %d km", ErrInvalidRadius, MaxNearbyKm)
+	}
+
+	edge, err := h3.HexagonEdgeLengthAvgKm(CellResolution)
+	if err != nil {
+		return nil, err
+	}
+
+	origin, err := h3.LatLngToCell(h3.LatLng(latlng), CellResolution)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v: %w", ErrInvalidLatLng, latlng, err)
+	}
+
+	// A cell holding a point within radiusKm has its centre within radiusKm plus an edge either
+	// side of the origin's, and each ring moves cell centres at least 1.5 edges further away
+	disk, err := origin.GridDisk(int(math.Ceil((radiusKm + 2*edge) / (1.5 * edge))))
+	if err != nil {
+		return nil, err
+	}
+
+	cells := make([]string, 0, len(disk))
+	for _, cell := range disk {
+		cells = append(cells, cell.String())
+	}
+
+	return cells, nil
+}
+
+// Nearest returns the waypoint nearest to latlng and its distance in km.
+func (w Waypoints) Nearest(latlng LatLng) (Waypoint, float64, error) {
+	if len(w) == 0 {
+		return Waypoint{}, 0, ErrNoWaypoints
+	}
+
+	point := Waypoint{LatLng: latlng}
+	nearest, nearestKm := w[0], math.Inf(1)
+	for _, waypoint := range w {
+		if km := point.DistanceTo(waypoint); km < nearestKm {
+			nearest, nearestKm = waypoint, km
+		}
+	}
+
+	return nearest, nearestKm, nil
+}
@@ -0,0 +1,112 @@
+package locations_test
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/AustinBayley/activity_tracker_api/pkg/locations"
+	"github.com/uber/h3-go/v4"
+)
+
+func TestCells(t *testing.T) {
+	cells, err := line(1000).Cells()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	// The line is about 111 km long, crossing a handful of cells
+	if len(cells) < 2 || len(cells) > 10 {
+		t.Errorf("expected a few distinct cells, got %d", len(cells))
+	}
+
+	for i, cell := range cells {
+		if slices.Contains(cells[i+1:], cell) {
+			t.Errorf("expected distinct cells, got %s more than once", cell)
+		}
+	}
+}
+
+func TestCellsWithin(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+
+	// Origins away from the centre of their cell can be closer to points in cells further out
+	for _, radius := range []float64{1, 5, 25, 100, locations.MaxNearbyKm} {
+		for range 20 {
+			origin := locations.Waypoint{LatLng: locations.LatLng{Lat: 51 + r.Float64(), Lng: -3 + r.Float64()}}
+			checkCellsWithin(t, r, origin, radius)
+		}
+	}
+}
+
+func checkCellsWithin(t *testing.T, r *rand.Rand, origin locations.Waypoint, radius float64) {
+	t.Helper()
+
+	cells, err := locations.CellsWithin(origin.LatLng, radius)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	// Every point within the radius must be in one of the cells, and the points near its edge are
+	// the likeliest to be missed
+	for range 1000 {
+		bearing, km := r.Float64()*2*math.Pi, radius*(0.9+0.1*r.Float64())
+		point := locations.Waypoint{LatLng: locations.LatLng{
+			Lat: origin.LatLng.Lat + km*math.Cos(bearing)/111,
+			Lng: origin.LatLng.Lng + km*math.Sin(bearing)/(111*math.Cos(origin.LatLng.Lat*math.Pi/180)),
+		}}
+		if origin.DistanceTo(point) > radius {
+			continue
+		}
+
+		cell, err := h3.LatLngToCell(h3.LatLng(point.LatLng), locations.CellResolution)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if !slices.Contains(cells, cell.String()) {
+			t.Fatalf("expected point %v, %f km away, to be in the cells within %f km", point.LatLng, origin.DistanceTo(point), radius)
+		}
+	}
+}
+
+func TestCellsWithinInvalid(t *testing.T) {
+	tests := []struct {
+		latlng locations.LatLng
+		radius float64
+		err    error
+	}{
+		{locations.LatLng{Lat: 51, Lng: 0}, 0, locations.ErrInvalidRadius},
+		{locations.LatLng{Lat: 51, Lng: 0}, locations.MaxNearbyKm + 1, locations.ErrInvalidRadius},
+		{locations.LatLng{Lat: 91, Lng: 0}, 10, locations.ErrInvalidLatLng},
+	}
+
+	for _, tt := range tests {
+		if _, err := locations.CellsWithin(tt.latlng, tt.radius); !errors.Is(err, tt.err) {
+			t.Errorf("expected %v for %v within %f km, got %v", tt.err, tt.latlng, tt.radius, err)
+		}
+	}
+}
+
+func TestNearest(t *testing.T) {
+	w := line(101)
+
+	nearest, km, err := w.Nearest(locations.LatLng{Lat: 0.01, Lng: 0.0502})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if nearest.LatLng.Lng != w[50].LatLng.Lng {
+		t.Errorf("expected nearest waypoint at lng %f, got %f", w[50].LatLng.Lng, nearest.LatLng.Lng)
+	}
+
+	if km < 1.1 || km > 1.2 {
+		t.Errorf("expected the point to be about 1.1 km away, got %f", km)
+	}
+
+	if _, _, err := (locations.Waypoints{}).Nearest(locations.LatLng{}); !errors.Is(err, locations.ErrNoWaypoints) {
+		t.Errorf("expected %v, got %v", locations.ErrNoWaypoints, err)
+	}
+}
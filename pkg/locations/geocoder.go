@@ -0,0 +1,197 @@
+package locations
+
+import (
+	"bufio"
+	"bytes"
+	_ "embed"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/uber/h3-go/v4"
+)
+
+const (
+	// gazetteerResolution is the H3 resolution places are indexed at, with cells roughly 40 km across.
+	gazetteerResolution = 4
+	// DefaultNearbyKm is how far a place can be from a point and still be considered near it.
+	DefaultNearbyKm = 100
+	// UnknownName is the name of locations not near any known place.
+	UnknownName = "unknown"
+)
+
+var (
+	ErrInvalidGazetteer = errors.New("invalid gazetteer")
+)
+
+//go:embed data/places.tsv
+var places []byte
+
+// Place is a named place on the map.
+type Place struct {
+	Name    string `json:"name" bson:"name"`
+	Country string `json:"country" bson:"country"`
+	LatLng  LatLng `json:"latlng" bson:"latlng"`
+}
+
+func (p Place) String() string {
+	if p.Country == "" {
+		return p.Name
+	}
+	return fmt.Sprintf("%s, %s", p.Name, p.Country)
+}
+
+// Geocoder finds named places from coordinates.
+type Geocoder interface {
+	// Reverse returns the place nearest to the given coordinates, or false if there is nothing nearby.
+	Reverse(latlng LatLng) (Place, bool)
+}
+
+// Gazetteer is an offline Geocoder over a fixed list of places, indexed by H3 cell.
+type Gazetteer struct {
+	cells    map[h3.Cell][]Place
+	nearbyKm float64
+	rings    int
+}
+
+// NewGazetteer indexes the given places. Places further than nearbyKm from a point are never
+// returned for it.
+func NewGazetteer(ps []Place, nearbyKm float64) (*Gazetteer, error) {
+	edge, err := h3.HexagonEdgeLengthAvgKm(gazetteerResolution)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidGazetteer, err)
+	}
+
+	g := &Gazetteer{
+		cells:    map[h3.Cell][]Place{},
+		nearbyKm: nearbyKm,
+		// Adjacent cell centres are at least 1.5 edges apart, so this many rings covers nearbyKm
+		rings: int(math.Ceil(nearbyKm/(1.5*edge))) + 1,
+	}
+
+	for _, p := range ps {
+		cell, err := h3.LatLngToCell(h3.LatLng(p.LatLng), gazetteerResolution)
+		if err != nil {
+			return nil, fmt.Errorf("%w: place %s: %w", ErrInvalidGazetteer, p, err)
+		}
+		g.cells[cell] = append(g.cells[cell], p)
+	}
+
+	return g, nil
+}
+
+// ParseGazetteer reads places from tab-separated lines of name, country, latitude and longitude.
+// Blank lines and lines starting with # are ignored.
+func ParseGazetteer(data []byte, nearbyKm float64) (*Gazetteer, error) {
+	ps := []Place{}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		fields := strings.Split(text, "\t")
+		if len(fields) != 4 {
+			return nil, fmt.Errorf("%w: line %d has %d fields", ErrInvalidGazetteer, line, len(fields))
+		}
+
+		lat, err := strconv.ParseFloat(fields[2], 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %w", ErrInvalidGazetteer, line, err)
+		}
+
+		lng, err := strconv.ParseFloat(fields[3], 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %w", ErrInvalidGazetteer, line, err)
+		}
+
+		ps = append(ps, Place{
+			Name:    fields[0],
+			Country: fields[1],
+			LatLng:  LatLng{Lat: lat, Lng: lng},
+		})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidGazetteer, err)
+	}
+
+	return NewGazetteer(ps, nearbyKm)
+}
+
+// Reverse searches outwards from the cell containing latlng, returning the nearest place within
+// range. The ring after the first containing a place is also searched, as it may hold a place
+// nearer than those in cells closer to the point.
+func (g *Gazetteer) Reverse(latlng LatLng) (Place, bool) {
+	origin, err := h3.LatLngToCell(h3.LatLng(latlng), gazetteerResolution)
+	if err != nil {
+		return Place{}, false
+	}
+
+	rings, err := origin.GridDiskDistances(g.rings)
+	if err != nil {
+		return Place{}, false
+	}
+
+	var nearest Place
+	nearestKm := math.Inf(1)
+	found := -1
+	for i, ring := range rings {
+		if found >= 0 && i > found+1 {
+			break
+		}
+
+		for _, cell := range ring {
+			for _, p := range g.cells[cell] {
+				km := h3.GreatCircleDistanceKm(h3.LatLng(latlng), h3.LatLng(p.LatLng))
+				if km < nearestKm {
+					nearest, nearestKm = p, km
+				}
+			}
+		}
+
+		if found < 0 && !math.IsInf(nearestKm, 1) {
+			found = i
+		}
+	}
+
+	if nearestKm > g.nearbyKm {
+		return Place{}, false
+	}
+
+	return nearest, true
+}
+
+var (
+	geocoderMu sync.RWMutex
+	geocoder   Geocoder
+)
+
+// defaultGeocoder is the embedded gazetteer, only parsed once it is first needed.
+var defaultGeocoder = sync.OnceValues(func() (*Gazetteer, error) {
+	return ParseGazetteer(places, DefaultNearbyKm)
+})
+
+// SetGeocoder replaces the Geocoder used to name locations. Passing nil restores the embedded gazetteer.
+func SetGeocoder(g Geocoder) {
+	geocoderMu.Lock()
+	defer geocoderMu.Unlock()
+	geocoder = g
+}
+
+// GetGeocoder returns the Geocoder used to name locations.
+func GetGeocoder() (Geocoder, error) {
+	geocoderMu.RLock()
+	defer geocoderMu.RUnlock()
+
+	if geocoder != nil {
+		return geocoder, nil
+	}
+
+	return defaultGeocoder()
+}
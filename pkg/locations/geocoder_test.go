@@ -0,0 +1,108 @@
+package locations_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/AustinBayley/activity_tracker_api/pkg/locations"
+)
+
+func TestLocationFromLatLng(t *testing.T) {
+	// The embedded places are generated, so only rely on places too large to be left out
+	tests := []struct {
+		name     string
+		latlng   locations.LatLng
+		expected string
+	}{
+		{"city centre", locations.LatLng{Lat: 51.45, Lng: -2.59}, "near Bristol, UK"},
+		{"mid atlantic", locations.LatLng{Lat: 40, Lng: -40}, locations.UnknownName},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loc, err := locations.LocationFromLatLng(tt.latlng)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			if !strings.HasPrefix(loc.Name, tt.expected) {
+				t.Errorf("expected %q, got %q", tt.expected, loc.Name)
+			}
+		})
+	}
+}
+
+func TestGazetteerReverse(t *testing.T) {
+	g, err := locations.NewGazetteer([]locations.Place{
+		{Name: "Bristol", Country: "UK", LatLng: locations.LatLng{Lat: 51.4552, Lng: -2.5967}},
+		{Name: "Bath", Country: "UK", LatLng: locations.LatLng{Lat: 51.3751, Lng: -2.3617}},
+	}, locations.DefaultNearbyKm)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		latlng   locations.LatLng
+		expected string
+	}{
+		{"outskirts", locations.LatLng{Lat: 51.50, Lng: -2.55}, "Bristol, UK"},
+		{"between places", locations.LatLng{Lat: 51.20, Lng: -2.20}, "Bath, UK"},
+		{"mid atlantic", locations.LatLng{Lat: 40, Lng: -40}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			place, ok := g.Reverse(tt.latlng)
+			if ok != (tt.expected != "") {
+				t.Fatalf("expected a place %v, got %v", tt.expected != "", ok)
+			}
+
+			if ok && place.String() != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, place.String())
+			}
+		})
+	}
+}
+
+func TestSetGeocoder(t *testing.T) {
+	g, err := locations.NewGazetteer([]locations.Place{
+		{Name: "Null Island", LatLng: locations.LatLng{Lat: 0, Lng: 0}},
+	}, 10)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	locations.SetGeocoder(g)
+	t.Cleanup(func() {
+		locations.SetGeocoder(nil)
+	})
+
+	loc, err := locations.LocationFromLatLng(locations.LatLng{Lat: 0.05, Lng: 0.05})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if loc.Name != "near Null Island" {
+		t.Errorf("expected near Null Island, got %q", loc.Name)
+	}
+
+	loc, err = locations.LocationFromLatLng(locations.LatLng{Lat: 1, Lng: 1})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if loc.Name != locations.UnknownName || loc.Place != nil {
+		t.Errorf("expected unknown location, got %+v", loc)
+	}
+}
+
+func TestParseGazetteerInvalid(t *testing.T) {
+	if _, err := locations.ParseGazetteer([]byte("Bristol\tUK\tnorth\t-2.59\n"), 10); err == nil {
+		t.Error("expected error for invalid latitude")
+	}
+
+	if _, err := locations.ParseGazetteer([]byte("Bristol\tUK\n"), 10); err == nil {
+		t.Error("expected error for missing fields")
+	}
+}
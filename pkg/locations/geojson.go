@@ -0,0 +1,99 @@
+package locations
+
+const (
+	// GeoJSONContentType is the media type of GeoJSON documents.
+	GeoJSONContentType = "application/geo+json"
+)
+
+// Geometry is a GeoJSON geometry. Coordinates are in longitude, latitude order.
+type Geometry struct {
+	Type        string      `json:"type"`
+	Coordinates interface{} `json:"coordinates"`
+}
+
+// Feature is a GeoJSON feature.
+type Feature struct {
+	Type       string                 `json:"type"`
+	Geometry   Geometry               `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+// FeatureCollection is a GeoJSON feature collection.
+type FeatureCollection struct {
+	Type     string    `json:"type"`
+	Features []Feature `json:"features"`
+}
+
+func NewFeatureCollection(features ...Feature) FeatureCollection {
+	if features == nil {
+		features = []Feature{}
+	}
+
+	return FeatureCollection{
+		Type:     "FeatureCollection",
+		Features: features,
+	}
+}
+
+func NewFeature(geometry Geometry, properties map[string]interface{}) Feature {
+	if properties == nil {
+		properties = map[string]interface{}{}
+	}
+
+	return Feature{
+		Type:       "Feature",
+		Geometry:   geometry,
+		Properties: properties,
+	}
+}
+
+// Position returns the GeoJSON position of the coordinates.
+func (l LatLng) Position() []float64 {
+	return []float64{l.Lng, l.Lat}
+}
+
+func NewPoint(l LatLng) Geometry {
+	return Geometry{
+		Type:        "Point",
+		Coordinates: l.Position(),
+	}
+}
+
+// NewLineString returns the waypoints as a LineString, which needs at least 2 waypoints to be
+// valid GeoJSON.
+func NewLineString(w Waypoints) Geometry {
+	positions := make([][]float64, 0, len(w))
+	for _, waypoint := range w {
+		positions = append(positions, waypoint.LatLng.Position())
+	}
+
+	return Geometry{
+		Type:        "LineString",
+		Coordinates: positions,
+	}
+}
+
+// RouteFeatures returns the waypoints as a LineString feature followed by a Point feature for
+// each checkpoint. A route of a single waypoint is a Point feature instead, and an empty route
+// has only its checkpoints. The index must be of the waypoints.
+func (w Waypoints) RouteFeatures(idx Index, properties map[string]interface{}) []Feature {
+	features := []Feature{}
+	switch len(w) {
+	case 0:
+	case 1:
+		features = append(features, NewFeature(NewPoint(w[0].LatLng), properties))
+	default:
+		features = append(features, NewFeature(NewLineString(w), properties))
+	}
+
+	for _, c := range w.Checkpoints(idx) {
+		features = append(features, NewFeature(NewPoint(c.LatLng), map[string]interface{}{
+			"name":        c.Name,
+			"description": c.Description,
+			"distance":    c.Distance,
+			"checkpoint":  true,
+		}))
+	}
+
+	return features
+}
@@ -0,0 +1,86 @@
+package locations_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/AustinBayley/activity_tracker_api/pkg/locations"
+)
+
+func TestRouteFeatures(t *testing.T) {
+	w := locations.Waypoints{
+		{LatLng: locations.LatLng{Lat: 50.07, Lng: -5.71}, Name: "Land's End", Checkpoint: true},
+		{LatLng: locations.LatLng{Lat: 51.45, Lng: -2.59}},
+		{LatLng: locations.LatLng{Lat: 58.64, Lng: -3.07}, Name: "John o' Groats", Checkpoint: true},
+	}
+
+	fc := locations.NewFeatureCollection(w.RouteFeatures(locations.NewIndex(w), map[string]interface{}{"name": "LEJOG"})...)
+
+	data, err := json.Marshal(fc)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	var decoded struct {
+		Type     string `json:"type"`
+		Features []struct {
+			Geometry struct {
+				Type        string          `json:"type"`
+				Coordinates json.RawMessage `json:"coordinates"`
+			} `json:"geometry"`
+			Properties map[string]interface{} `json:"properties"`
+		} `json:"features"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if decoded.Type != "FeatureCollection" || len(decoded.Features) != 3 {
+		t.Fatalf("expected feature collection with 3 features, got %s", data)
+	}
+
+	line := decoded.Features[0]
+	if line.Geometry.Type != "LineString" || string(line.Geometry.Coordinates) != "[[-5.71,50.07],[-2.59,51.45],[-3.07,58.64]]" {
+		t.Errorf("expected line string in lng, lat order, got %s", line.Geometry.Coordinates)
+	}
+
+	if line.Properties["name"] != "LEJOG" {
+		t.Errorf("expected route properties, got %v", line.Properties)
+	}
+
+	finish := decoded.Features[2]
+	if finish.Geometry.Type != "Point" || finish.Properties["name"] != "John o' Groats" {
+		t.Errorf("expected finish checkpoint, got %+v", finish)
+	}
+
+	if d, _ := finish.Properties["distance"].(float64); d < 900 {
+		t.Errorf("expected finish to be over 900 km along the route, got %v", finish.Properties["distance"])
+	}
+}
+
+func TestRouteFeaturesShortRoutes(t *testing.T) {
+	start := locations.Waypoint{LatLng: locations.LatLng{Lat: 50.07, Lng: -5.71}, Name: "Land's End", Checkpoint: true}
+
+	for _, tt := range []struct {
+		name      string
+		waypoints locations.Waypoints
+		types     []string
+	}{
+		{name: "empty", waypoints: locations.Waypoints{}, types: []string{}},
+		{name: "single waypoint", waypoints: locations.Waypoints{start}, types: []string{"Point", "Point"}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			features := tt.waypoints.RouteFeatures(locations.NewIndex(tt.waypoints), nil)
+
+			types := []string{}
+			for _, f := range features {
+				types = append(types, f.Geometry.Type)
+			}
+
+			if fmt.Sprint(types) != fmt.Sprint(tt.types) {
+				t.Errorf("expected geometries %v, got %v", tt.types, types)
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package locations
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	// geoNamesColumns is the number of tab-separated columns in a GeoNames dump, see
+	// https://download.geonames.org/export/dump/readme.txt.
+	geoNamesColumns = 19
+	// countryInfoColumns is the least number of columns in the GeoNames country information,
+	// up to and including the country's name.
+	countryInfoColumns = 5
+)
+
+// shortCountryNames are the names used in place of the GeoNames country names, so locations are
+// named the way people refer to them, e.g. "near Bristol, UK".
+var shortCountryNames = map[string]string{
+	"AE": "UAE",
+	"GB": "UK",
+	"US": "USA",
+}
+
+// ReadCountryNames reads the name of each country by its ISO 3166 code from the GeoNames country
+// information, countryInfo.txt.
+func ReadCountryNames(r io.Reader) (map[string]string, error) {
+	names := map[string]string{}
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if strings.TrimSpace(text) == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		fields := strings.Split(text, "\t")
+		if len(fields) < countryInfoColumns {
+			return nil, fmt.Errorf("%w: line %d has %d fields", ErrInvalidGazetteer, line, len(fields))
+		}
+
+		names[fields[0]] = fields[4]
+		if short, ok := shortCountryNames[fields[0]]; ok {
+			names[fields[0]] = short
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidGazetteer, err)
+	}
+
+	return names, nil
+}
+
+// ReadGeoNames reads places from a GeoNames dump such as cities15000.txt, keeping those with a
+// population of at least minPopulation. Countries are named by looking up their ISO 3166 codes
+// in countries, as read by ReadCountryNames.
+func ReadGeoNames(r io.Reader, minPopulation int64, countries map[string]string) ([]Place, error) {
+	ps := []Place{}
+
+	scanner := bufio.NewScanner(r)
+	// Lines include every alternate name of a place, which can be long
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+
+		fields := strings.Split(scanner.Text(), "\t")
+		if len(fields) != geoNamesColumns {
+			return nil, fmt.Errorf("%w: line %d has %d fields", ErrInvalidGazetteer, line, len(fields))
+		}
+
+		population, err := strconv.ParseInt(fields[14], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %w", ErrInvalidGazetteer, line, err)
+		}
+
+		if population < minPopulation {
+			continue
+		}
+
+		lat, err := strconv.ParseFloat(fields[4], 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %w", ErrInvalidGazetteer, line, err)
+		}
+
+		lng, err := strconv.ParseFloat(fields[5], 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %w", ErrInvalidGazetteer, line, err)
+		}
+
+		country, ok := countries[fields[8]]
+		if !ok {
+			return nil, fmt.Errorf("%w: line %d has unknown country %q", ErrInvalidGazetteer, line, fields[8])
+		}
+
+		ps = append(ps, Place{
+			Name:    fields[1],
+			Country: country,
+			LatLng:  LatLng{Lat: lat, Lng: lng},
+		})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidGazetteer, err)
+	}
+
+	return ps, nil
+}
+
+// WriteGazetteer writes places in the format read by ParseGazetteer, sorted by country and then
+// name, after the given comment lines. Coordinates are rounded to 4 decimal places, around 10 m.
+func WriteGazetteer(w io.Writer, ps []Place, comments ...string) error {
+	sorted := slices.Clone(ps)
+	slices.SortStableFunc(sorted, func(a, b Place) int {
+		if c := strings.Compare(a.Country, b.Country); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	bw := bufio.NewWriter(w)
+	for _, c := range comments {
+		fmt.Fprintf(bw, "# %s\n", c)
+	}
+	fmt.Fprintln(bw, "# name\tcountry\tlat\tlng")
+
+	round := func(v float64) string {
+		return strconv.FormatFloat(math.Round(v*1e4)/1e4, 'f', -1, 64)
+	}
+	for _, p := range sorted {
+		fmt.Fprintf(bw, "%s\t%s\t%s\t%s\n", p.Name, p.Country, round(p.LatLng.Lat), round(p.LatLng.Lng))
+	}
+
+	return bw.Flush()
+}
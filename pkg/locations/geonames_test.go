@@ -0,0 +1,101 @@
+package locations_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/AustinBayley/activity_tracker_api/pkg/locations"
+)
+
+// geoNamesRow returns a line of a GeoNames dump with the given fields filled in.
+func geoNamesRow(name, lat, lng, country, population string) string {
+	fields := make([]string, 19)
+	fields[0] = "1"
+	fields[1] = name
+	fields[2] = name
+	fields[3] = "Alternate,Names"
+	fields[4] = lat
+	fields[5] = lng
+	fields[6] = "P"
+	fields[7] = "PPL"
+	fields[8] = country
+	fields[14] = population
+	fields[17] = "Europe/London"
+	fields[18] = "2024-01-01"
+	return strings.Join(fields, "\t")
+}
+
+// countryInfo is an extract of the GeoNames country information.
+const countryInfo = `#ISO	ISO3	ISO-Numeric	fips	Country	Capital
+FR	FRA	250	FR	France	Paris
+GB	GBR	826	UK	United Kingdom	London
+`
+
+func TestReadCountryNames(t *testing.T) {
+	countries, err := locations.ReadCountryNames(strings.NewReader(countryInfo))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	// Countries are known by their short names where they have one
+	if countries["FR"] != "France" || countries["GB"] != "UK" || len(countries) != 2 {
+		t.Errorf("expected France and UK, got %v", countries)
+	}
+}
+
+func TestReadGeoNames(t *testing.T) {
+	countries, err := locations.ReadCountryNames(strings.NewReader(countryInfo))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	dump := strings.Join([]string{
+		geoNamesRow("Bristol", "51.45523", "-2.59665", "GB", "430713"),
+		geoNamesRow("Bath", "51.3751", "-2.36172", "GB", "94782"),
+		geoNamesRow("Frome", "51.22834", "-2.32211", "GB", "26203"),
+	}, "\n")
+
+	ps, err := locations.ReadGeoNames(strings.NewReader(dump), 50000, countries)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(ps) != 2 {
+		t.Fatalf("expected 2 places, got %d", len(ps))
+	}
+
+	// Written places can be read back by the gazetteer
+	buf := bytes.Buffer{}
+	if err := locations.WriteGazetteer(&buf, ps, "From a test"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if !strings.HasPrefix(buf.String(), "# From a test\n") || !strings.Contains(buf.String(), "Bath\tUK\t51.3751\t-2.3617\n") {
+		t.Errorf("unexpected gazetteer:\n%s", buf.String())
+	}
+
+	g, err := locations.ParseGazetteer(buf.Bytes(), 10)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if place, ok := g.Reverse(locations.LatLng{Lat: 51.45, Lng: -2.59}); !ok || place.String() != "Bristol, UK" {
+		t.Errorf("expected Bristol, UK, got %v", place)
+	}
+}
+
+func TestReadGeoNamesInvalid(t *testing.T) {
+	for _, dump := range []string{
+		"Bristol\tGB\t51.45\t-2.59",
+		geoNamesRow("Bristol", "north", "-2.59665", "GB", "430713"),
+		geoNamesRow("Bristol", "51.45523", "-2.59665", "GB", "many"),
+		geoNamesRow("Bristol", "51.45523", "-2.59665", "XX", "430713"),
+	} {
+		countries := map[string]string{"GB": "UK"}
+		if _, err := locations.ReadGeoNames(strings.NewReader(dump), 0, countries); !errors.Is(err, locations.ErrInvalidGazetteer) {
+			t.Errorf("expected error %v, got %v", locations.ErrInvalidGazetteer, err)
+		}
+	}
+}
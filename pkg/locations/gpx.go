@@ -0,0 +1,105 @@
+package locations
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+var (
+	ErrInvalidGPX = errors.New("invalid gpx")
+)
+
+// GPX is a GPS Exchange Format document, limited to its tracks and routes.
+type GPX struct {
+	XMLName xml.Name   `xml:"gpx"`
+	Tracks  []GPXTrack `xml:"trk"`
+	Routes  []GPXRoute `xml:"rte"`
+}
+
+type GPXTrack struct {
+	Name     string            `xml:"name"`
+	Type     string            `xml:"type"`
+	Segments []GPXTrackSegment `xml:"trkseg"`
+}
+
+type GPXTrackSegment struct {
+	Points []GPXPoint `xml:"trkpt"`
+}
+
+type GPXRoute struct {
+	Name   string     `xml:"name"`
+	Points []GPXPoint `xml:"rtept"`
+}
+
+// GPXPoint is a track or route point. Elevation and time are optional.
+type GPXPoint struct {
+	Lat         float64    `xml:"lat,attr"`
+	Lon         float64    `xml:"lon,attr"`
+	Elevation   *float64   `xml:"ele"`
+	Time        *time.Time `xml:"time"`
+	Name        string     `xml:"name"`
+	Description string     `xml:"desc"`
+}
+
+func (p GPXPoint) Waypoint() Waypoint {
+	return Waypoint{
+		LatLng:      LatLng{Lat: p.Lat, Lng: p.Lon},
+		Name:        p.Name,
+		Description: p.Description,
+	}
+}
+
+// ParseGPX decodes a GPX document.
+func ParseGPX(r io.Reader) (*GPX, error) {
+	gpx := GPX{}
+	if err := xml.NewDecoder(r).Decode(&gpx); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidGPX, err)
+	}
+
+	for _, p := range gpx.Points() {
+		if !p.Waypoint().LatLng.Valid() {
+			return nil, fmt.Errorf("%w: point %f,%f out of range", ErrInvalidGPX, p.Lat, p.Lon)
+		}
+	}
+
+	return &gpx, nil
+}
+
+// Points returns the points of every track segment in order or, if there are no tracks, the
+// points of every route.
+func (g *GPX) Points() []GPXPoint {
+	points := []GPXPoint{}
+	for _, t := range g.Tracks {
+		for _, s := range t.Segments {
+			points = append(points, s.Points...)
+		}
+	}
+
+	if len(points) > 0 {
+		return points
+	}
+
+	for _, r := range g.Routes {
+		points = append(points, r.Points...)
+	}
+
+	return points
+}
+
+// Waypoints returns the points of the document as waypoints, see Points.
+func (g *GPX) Waypoints() (Waypoints, error) {
+	points := g.Points()
+	if len(points) == 0 {
+		return nil, ErrNoWaypoints
+	}
+
+	waypoints := make(Waypoints, 0, len(points))
+	for _, p := range points {
+		waypoints = append(waypoints, p.Waypoint())
+	}
+
+	return waypoints, nil
+}
@@ -0,0 +1,105 @@
+package locations_test
+
+import (
+	"errors"
+	"math"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/AustinBayley/activity_tracker_api/pkg/locations"
+)
+
+func parseGPXFile(t *testing.T, name string) *locations.GPX {
+	t.Helper()
+
+	f, err := os.Open(name)
+	if err != nil {
+		t.Fatalf("failed to open %s: %v", name, err)
+	}
+	defer f.Close()
+
+	gpx, err := locations.ParseGPX(f)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	return gpx
+}
+
+func TestParseGPXTrack(t *testing.T) {
+	gpx := parseGPXFile(t, "testdata/track.gpx")
+
+	waypoints, err := gpx.Waypoints()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	// Both segments are joined into one route
+	if len(waypoints) != 5 {
+		t.Fatalf("expected 5 waypoints, got %d", len(waypoints))
+	}
+
+	// 0.02 degrees of longitude at 51.45 degrees north
+	expected := 0.02 * math.Pi / 180 * 6371.0088 * math.Cos(51.45*math.Pi/180)
+	if d := waypoints.Distance(); math.Abs(d-expected) > 0.01 {
+		t.Errorf("expected distance of %f km, got %f", expected, d)
+	}
+
+	points := gpx.Points()
+	if points[0].Elevation == nil || *points[0].Elevation != 10 {
+		t.Errorf("expected elevation of 10, got %v", points[0].Elevation)
+	}
+
+	if points[4].Time == nil || points[4].Time.Sub(*points[0].Time).Minutes() != 8 {
+		t.Errorf("expected track to last 8 minutes, got %v", points[4].Time)
+	}
+}
+
+func TestParseGPXRoute(t *testing.T) {
+	gpx := parseGPXFile(t, "testdata/route.gpx")
+
+	waypoints, err := gpx.Waypoints()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(waypoints) != 6 {
+		t.Fatalf("expected 6 waypoints, got %d", len(waypoints))
+	}
+
+	if waypoints.First().Name != "Land's End" || waypoints.First().Description != "The start" {
+		t.Errorf("expected route to start at Land's End, got %+v", waypoints.First())
+	}
+
+	if waypoints.Last().Name != "John o' Groats" {
+		t.Errorf("expected route to finish at John o' Groats, got %+v", waypoints.Last())
+	}
+}
+
+func TestParseGPXInvalid(t *testing.T) {
+	tests := map[string]string{
+		"not xml":      "runner's log",
+		"out of range": `<gpx><rte><rtept lat="91" lon="0"></rtept></rte></gpx>`,
+		"bad latitude": `<gpx><rte><rtept lat="north" lon="0"></rtept></rte></gpx>`,
+	}
+
+	for name, doc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := locations.ParseGPX(strings.NewReader(doc)); !errors.Is(err, locations.ErrInvalidGPX) {
+				t.Errorf("expected ErrInvalidGPX, got %v", err)
+			}
+		})
+	}
+}
+
+func TestParseGPXEmpty(t *testing.T) {
+	gpx, err := locations.ParseGPX(strings.NewReader(`<gpx><trk><trkseg></trkseg></trk></gpx>`))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if _, err := gpx.Waypoints(); !errors.Is(err, locations.ErrNoWaypoints) {
+		t.Errorf("expected ErrNoWaypoints, got %v", err)
+	}
+}
@@ -0,0 +1,177 @@
+package locations
+
+import (
+	"container/heap"
+	"errors"
+	"fmt"
+	"math"
+)
+
+const (
+	// DefaultTolerance is how far in metres a simplified route may stray from the original.
+	DefaultTolerance = 5.0
+	// MaxWaypoints is the most waypoints a simplified route may have. Waypoints beyond the
+	// tolerance are removed until a route fits, and a route with more named waypoints and
+	// checkpoints than this can't be simplified.
+	MaxWaypoints = 5000
+	// earthRadiusM is the mean radius used by h3.GreatCircleDistanceKm.
+	earthRadiusM = 6371007.180918475
+)
+
+var (
+	ErrTooManyWaypoints = errors.New("too many named waypoints and checkpoints")
+)
+
+// Simplification reports how much a route was changed by being simplified.
+type Simplification struct {
+	// Tolerance is the tolerance in metres that was used, which is greater than the one asked for
+	// if waypoints further away had to be removed to fit the maximum.
+	Tolerance         float64 `json:"tolerance" bson:"tolerance"`
+	OriginalWaypoints int     `json:"originalWaypoints" bson:"originalWaypoints"`
+	Waypoints         int     `json:"waypoints" bson:"waypoints"`
+	// OriginalDistance and Distance are in km.
+	OriginalDistance float64 `json:"originalDistance" bson:"originalDistance"`
+	Distance         float64 `json:"distance" bson:"distance"`
+}
+
+// Simplify removes the waypoints which stray least from the line between their neighbours, one
+// at a time, until every waypoint left strays further than tolerance metres and at most max
+// remain. This is Visvalingam's algorithm measured by distance rather than area, so it takes
+// O(n log n) time however the route zig-zags. The first and last waypoints and any named
+// waypoints or checkpoints are always kept, and ErrTooManyWaypoints is returned if there are
+// more of them than max.
+func (w Waypoints) Simplify(tolerance float64, max int) (Waypoints, Simplification, error) {
+	s := Simplification{
+		Tolerance:         tolerance,
+		OriginalWaypoints: len(w),
+		OriginalDistance:  w.Distance(),
+	}
+
+	keep := 0
+	for i, waypoint := range w {
+		if i == 0 || i == len(w)-1 || waypoint.Checkpoint || waypoint.Name != "" {
+			keep++
+		}
+	}
+
+	if max > 0 && keep > max {
+		return nil, s, fmt.Errorf("%w: %d is more than %d", ErrTooManyWaypoints, keep, max)
+	}
+
+	simplified, removed := w.visvalingam(tolerance, max)
+	s.Tolerance = math.Max(s.Tolerance, removed)
+	s.Waypoints = len(simplified)
+	s.Distance = simplified.Distance()
+	return simplified, s, nil
+}
+
+// visvalingam simplifies the waypoints, returning the largest distance in metres of a removed
+// waypoint from the line between its neighbours when it was removed.
+func (w Waypoints) visvalingam(tolerance float64, max int) (Waypoints, float64) {
+	if len(w) < 3 {
+		return w, 0
+	}
+
+	// Waypoints still in the route are linked to their neighbours
+	prev, next := make([]int, len(w)), make([]int, len(w))
+	q := &waypointQueue{
+		deviation: make([]float64, len(w)),
+		position:  make([]int, len(w)),
+	}
+	for i, waypoint := range w {
+		prev[i], next[i] = i-1, i+1
+		q.deviation[i] = math.Inf(1)
+		if i > 0 && i < len(w)-1 && !waypoint.Checkpoint && waypoint.Name == "" {
+			q.deviation[i] = segmentDistanceM(waypoint.LatLng, w[i-1].LatLng, w[i+1].LatLng)
+		}
+		q.position[i] = len(q.indices)
+		q.indices = append(q.indices, i)
+	}
+	heap.Init(q)
+
+	remaining, removed := len(w), 0.0
+	for q.Len() > 0 {
+		i := q.indices[0]
+		d := q.deviation[i]
+		if math.IsInf(d, 1) || (d > tolerance && (max <= 0 || remaining <= max)) {
+			break
+		}
+
+		heap.Pop(q)
+		remaining--
+		removed = math.Max(removed, d)
+
+		p, n := prev[i], next[i]
+		next[p], prev[n] = n, p
+
+		// A neighbour strays at least as far as the waypoints removed beside it, so the
+		// waypoints are removed in order of how far they stray
+		for _, j := range []int{p, n} {
+			if math.IsInf(q.deviation[j], 1) {
+				continue
+			}
+			q.deviation[j] = math.Max(d, segmentDistanceM(w[j].LatLng, w[prev[j]].LatLng, w[next[j]].LatLng))
+			heap.Fix(q, q.position[j])
+		}
+	}
+
+	simplified := make(Waypoints, 0, remaining)
+	for i := 0; i < len(w); i = next[i] {
+		simplified = append(simplified, w[i])
+	}
+
+	return simplified, removed
+}
+
+// waypointQueue is a min-heap of waypoint indices by how far each strays from its neighbours,
+// which tracks the position of each waypoint so it can be fixed when its neighbours change.
+type waypointQueue struct {
+	indices   []int
+	deviation []float64
+	position  []int
+}
+
+func (q *waypointQueue) Len() int {
+	return len(q.indices)
+}
+
+func (q *waypointQueue) Less(a, b int) bool {
+	return q.deviation[q.indices[a]] < q.deviation[q.indices[b]]
+}
+
+func (q *waypointQueue) Swap(a, b int) {
+	q.indices[a], q.indices[b] = q.indices[b], q.indices[a]
+	q.position[q.indices[a]], q.position[q.indices[b]] = a, b
+}
+
+func (q *waypointQueue) Push(x any) {
+	q.position[x.(int)] = len(q.indices)
+	q.indices = append(q.indices, x.(int))
+}
+
+func (q *waypointQueue) Pop() any {
+	i := q.indices[len(q.indices)-1]
+	q.indices = q.indices[:len(q.indices)-1]
+	return i
+}
+
+// segmentDistanceM returns the distance in metres from p to the segment between a and b, using
+// an equirectangular projection centred on a which is accurate over the lengths of route legs.
+func segmentDistanceM(p LatLng, a LatLng, b LatLng) float64 {
+	scale := math.Cos((a.Lat + b.Lat) / 2 * math.Pi / 180)
+	project := func(l LatLng) (float64, float64) {
+		// Wrap longitude differences across the antimeridian
+		dLng := math.Remainder(l.Lng-a.Lng, 360)
+		return dLng * math.Pi / 180 * scale * earthRadiusM, (l.Lat - a.Lat) * math.Pi / 180 * earthRadiusM
+	}
+
+	px, py := project(p)
+	bx, by := project(b)
+
+	t := 0.0
+	if length := bx*bx + by*by; length > 0 {
+		t = math.Max(0, math.Min(1, (px*bx+py*by)/length))
+	}
+
+	return math.Hypot(px-t*bx, py-t*by)
+}
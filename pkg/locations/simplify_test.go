@@ -0,0 +1,136 @@
+package locations_test
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"testing"
+
+	"github.com/AustinBayley/activity_tracker_api/pkg/locations"
+)
+
+// wiggle returns n waypoints heading east along the equator, alternating offset north and south
+// by offset degrees.
+func wiggle(n int, offset float64) locations.Waypoints {
+	w := make(locations.Waypoints, 0, n)
+	for i := range n {
+		lat := offset
+		if i%2 == 1 {
+			lat = -offset
+		}
+		w = append(w, locations.Waypoint{LatLng: locations.LatLng{Lat: lat, Lng: float64(i) * 0.001}})
+	}
+	return w
+}
+
+func TestSimplifyWithinTolerance(t *testing.T) {
+	// Roughly 1m either side of the equator
+	w := wiggle(1000, 0.00001)
+
+	simplified, s, err := w.Simplify(5, 0)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(simplified) != 2 {
+		t.Fatalf("expected 2 waypoints, got %d", len(simplified))
+	}
+
+	if s.OriginalWaypoints != 1000 || s.Waypoints != 2 || s.Tolerance != 5 {
+		t.Errorf("unexpected simplification report %+v", s)
+	}
+
+	if s.Distance >= s.OriginalDistance || math.Abs(s.Distance-simplified.Distance()) > 1e-9 {
+		t.Errorf("expected simplified distance %f to be less than original %f", s.Distance, s.OriginalDistance)
+	}
+}
+
+func TestSimplifyKeepsDeviations(t *testing.T) {
+	// Roughly 100m either side of the equator
+	w := wiggle(100, 0.001)
+
+	simplified, _, err := w.Simplify(5, 0)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(simplified) != len(w) {
+		t.Errorf("expected all %d waypoints to be kept, got %d", len(w), len(simplified))
+	}
+}
+
+func TestSimplifyKeepsCheckpoints(t *testing.T) {
+	w := wiggle(1000, 0)
+	w[500].Checkpoint = true
+	w[500].Name = "Halfway"
+
+	simplified, _, err := w.Simplify(5, 0)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(simplified) != 3 || simplified[1].Name != "Halfway" {
+		t.Errorf("expected start, checkpoint and end, got %+v", simplified)
+	}
+}
+
+func TestSimplifyKeepsNamedWaypoints(t *testing.T) {
+	w := wiggle(1000, 0)
+	w[250].Name = "Bridge"
+	w[750].Name = "Pub"
+
+	simplified, _, err := w.Simplify(5, 0)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(simplified) != 4 || simplified[1].Name != "Bridge" || simplified[2].Name != "Pub" {
+		t.Errorf("expected start, named waypoints and end, got %+v", simplified)
+	}
+}
+
+func TestSimplifyMaxWaypoints(t *testing.T) {
+	w := wiggle(1000, 0.001)
+
+	simplified, s, err := w.Simplify(5, 100)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(simplified) > 100 {
+		t.Errorf("expected at most 100 waypoints, got %d", len(simplified))
+	}
+
+	if s.Tolerance <= 5 {
+		t.Errorf("expected tolerance to be increased, got %f", s.Tolerance)
+	}
+}
+
+func TestSimplifyTooManyNamedWaypoints(t *testing.T) {
+	w := wiggle(101, 0)
+	for i := range w {
+		w[i].Name = "Named"
+	}
+
+	if _, _, err := w.Simplify(5, 100); !errors.Is(err, locations.ErrTooManyWaypoints) {
+		t.Errorf("expected %v, got %v", locations.ErrTooManyWaypoints, err)
+	}
+
+	if _, _, err := w[:100].Simplify(5, 100); err != nil {
+		t.Errorf("expected no error at the limit, got %v", err)
+	}
+}
+
+func BenchmarkSimplify(b *testing.B) {
+	// Roughly 10m either side of the equator, so every waypoint strays beyond the tolerance and
+	// the longer routes have to be cut down to the maximum
+	for _, n := range []int{5000, 20000, 50000} {
+		w := wiggle(n, 0.0001)
+
+		b.Run(strconv.Itoa(n), func(b *testing.B) {
+			for b.Loop() {
+				_, _, _ = w.Simplify(locations.DefaultTolerance, locations.MaxWaypoints)
+			}
+		})
+	}
+}
@@ -1,16 +1,28 @@
 package locations
 
 import (
+	"errors"
 	"math"
+	"sort"
 
 	"github.com/uber/h3-go/v4"
 )
 
+var (
+	ErrNoWaypoints  = errors.New("no waypoints")
+	ErrInvalidIndex = errors.New("index does not match waypoints")
+)
+
 type LatLng struct {
 	Lat float64 `json:"lat" bson:"lat"`
 	Lng float64 `json:"lng" bson:"lng"`
 }
 
+// Valid reports whether the coordinates are within range.
+func (l LatLng) Valid() bool {
+	return l.Lat >= -90 && l.Lat <= 90 && l.Lng >= -180 && l.Lng <= 180
+}
+
 func (l LatLng) AsRadians() LatLng {
 	return LatLng{
 		Lat: l.Lat * math.Pi / 180,
@@ -19,7 +31,11 @@ func (l LatLng) AsRadians() LatLng {
 }
 
 type Waypoint struct {
-	LatLng LatLng `json:"latlng" bson:"latlng"`
+	LatLng      LatLng `json:"latlng" bson:"latlng"`
+	Name        string `json:"name,omitempty" bson:"name,omitempty"`
+	Description string `json:"description,omitempty" bson:"description,omitempty"`
+	// Checkpoint marks the waypoint as an intermediate goal along the route.
+	Checkpoint bool `json:"checkpoint,omitempty" bson:"checkpoint,omitempty"`
 }
 
 // DistanceTo returns distance between 2 waypoints in km
@@ -37,16 +53,80 @@ func (w Waypoints) Last() Waypoint {
 	return w[len(w)-1]
 }
 
+// Distance returns the total distance along the waypoints in km.
+func (w Waypoints) Distance() float64 {
+	return NewIndex(w).Total()
+}
+
+// Index is the cumulative distance in km from the first waypoint of a route to each waypoint,
+// so positions along the route can be found without recalculating the length of every leg.
+type Index []float64
+
+// NewIndex calculates the cumulative distance to each of the waypoints.
+func NewIndex(w Waypoints) Index {
+	idx := make(Index, len(w))
+	for i := 1; i < len(w); i++ {
+		idx[i] = idx[i-1] + w[i-1].DistanceTo(w[i])
+	}
+	return idx
+}
+
+// Total returns the distance to the last waypoint in km.
+func (idx Index) Total() float64 {
+	if len(idx) == 0 {
+		return 0
+	}
+	return idx[len(idx)-1]
+}
+
+// Checkpoint is a waypoint marked as a checkpoint, along with how far along the route it is.
+type Checkpoint struct {
+	Waypoint `json:",inline" bson:",inline"`
+	// Distance is the distance in km from the start of the route to the checkpoint.
+	Distance float64 `json:"distance" bson:"distance"`
+}
+
+// Checkpoints returns the waypoints marked as checkpoints, in route order. The index must be of
+// the waypoints.
+func (w Waypoints) Checkpoints(idx Index) []Checkpoint {
+	checkpoints := []Checkpoint{}
+	for i, waypoint := range w {
+		if waypoint.Checkpoint {
+			checkpoints = append(checkpoints, Checkpoint{
+				Waypoint: waypoint,
+				Distance: idx[i],
+			})
+		}
+	}
+
+	return checkpoints
+}
+
 type Location struct {
 	LatLng LatLng `json:"latlng" bson:"latlng"`
 	Name   string `json:"name" bson:"name"`
+	// Place is the nearest named place, if there is one nearby.
+	Place *Place `json:"place,omitempty" bson:"place,omitempty"`
 }
 
+// LocationFromLatLng names the location after the nearest place found by the Geocoder.
 func LocationFromLatLng(latlng LatLng) (Location, error) {
-	return Location{
+	loc := Location{
 		LatLng: latlng,
-		Name:   "unknown",
-	}, nil
+		Name:   UnknownName,
+	}
+
+	geocoder, err := GetGeocoder()
+	if err != nil {
+		return loc, err
+	}
+
+	if place, ok := geocoder.Reverse(latlng); ok {
+		loc.Name = "near " + place.String()
+		loc.Place = &place
+	}
+
+	return loc, nil
 }
 
 // getNewCoordinates calculates a new LatLng point at a given distance from the start point towards the end point.
@@ -76,25 +156,32 @@ func getNewCoordinates(start LatLng, end LatLng, distance float64) LatLng {
 	}
 }
 
-// GetLocation iterates over the waypoints and returns the location when the distance (total distance travelled by user) is reached
+// GetLocation returns the location when the distance (total distance travelled by user) is reached.
+// It indexes the waypoints on every call, so Locate should be used for repeated lookups.
 func (w Waypoints) GetLocation(distance float64) (Location, error) {
-	previous := w[0]
-
-	var distanceSum float64 = 0
-	// Iterate over each leg of the route and calculate the distance sum
-	for _, waypoint := range w[1:] {
+	return w.Locate(NewIndex(w), distance)
+}
 
-		diff := previous.DistanceTo(waypoint)
-		distanceSum += diff
+// Locate returns the location when the distance (total distance travelled by user) is reached,
+// using the index to find the leg of the route it is on. The index must be of the waypoints.
+func (w Waypoints) Locate(idx Index, distance float64) (Location, error) {
+	if len(w) == 0 {
+		return Location{}, ErrNoWaypoints
+	}
 
-		// Check if the total distance has been reached
-		if distanceSum >= distance {
-			// Get new location by getting the distance between the previous and next waypoint
-			return LocationFromLatLng(getNewCoordinates(previous.LatLng, waypoint.LatLng, distance-(distanceSum-diff)))
-		}
+	if len(idx) != len(w) {
+		return Location{}, ErrInvalidIndex
+	}
 
-		previous = waypoint
+	// Find the first waypoint at or beyond the distance
+	i := sort.SearchFloat64s(idx, distance)
+	switch {
+	case i == 0:
+		return LocationFromLatLng(w.First().LatLng)
+	case i == len(w):
+		return LocationFromLatLng(w.Last().LatLng)
 	}
 
-	return LocationFromLatLng(previous.LatLng)
+	// Get new location by getting the distance between the previous and next waypoint
+	return LocationFromLatLng(getNewCoordinates(w[i-1].LatLng, w[i].LatLng, distance-idx[i-1]))
 }
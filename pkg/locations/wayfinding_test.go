@@ -0,0 +1,116 @@
+package locations_test
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/AustinBayley/activity_tracker_api/pkg/locations"
+)
+
+// line returns n waypoints heading east along the equator, each 0.001 degrees apart.
+func line(n int) locations.Waypoints {
+	w := make(locations.Waypoints, 0, n)
+	for i := range n {
+		w = append(w, locations.Waypoint{LatLng: locations.LatLng{Lat: 0, Lng: float64(i) * 0.001}})
+	}
+	return w
+}
+
+// linearLocation walks every leg of the route to find the location, as GetLocation did before
+// routes were indexed.
+func linearLocation(w locations.Waypoints, distance float64) locations.LatLng {
+	previous := w[0]
+
+	var distanceSum float64 = 0
+	for _, waypoint := range w[1:] {
+		diff := previous.DistanceTo(waypoint)
+		distanceSum += diff
+
+		if distanceSum >= distance {
+			fraction := (distance - (distanceSum - diff)) / diff
+			return locations.LatLng{
+				Lat: previous.LatLng.Lat + fraction*(waypoint.LatLng.Lat-previous.LatLng.Lat),
+				Lng: previous.LatLng.Lng + fraction*(waypoint.LatLng.Lng-previous.LatLng.Lng),
+			}
+		}
+
+		previous = waypoint
+	}
+
+	return previous.LatLng
+}
+
+type nowhere struct{}
+
+func (nowhere) Reverse(locations.LatLng) (locations.Place, bool) {
+	return locations.Place{}, false
+}
+
+func TestLocate(t *testing.T) {
+	w := line(1000)
+	idx := locations.NewIndex(w)
+
+	if math.Abs(idx.Total()-w.Distance()) > 1e-9 {
+		t.Errorf("expected index total %f to match distance %f", idx.Total(), w.Distance())
+	}
+
+	for _, distance := range []float64{0, 0.05, 12.3456, 55.5, idx.Total(), idx.Total() + 10} {
+		loc, err := w.Locate(idx, distance)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		expected := linearLocation(w, distance)
+		if math.Abs(loc.LatLng.Lat-expected.Lat) > 1e-6 || math.Abs(loc.LatLng.Lng-expected.Lng) > 1e-6 {
+			t.Errorf("expected %+v at %f km, got %+v", expected, distance, loc.LatLng)
+		}
+	}
+
+	// Distances before the start of the route are at the start
+	loc, err := w.Locate(idx, -1)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if loc.LatLng != w.First().LatLng {
+		t.Errorf("expected start of route, got %+v", loc.LatLng)
+	}
+}
+
+func TestLocateInvalid(t *testing.T) {
+	if _, err := (locations.Waypoints{}).Locate(locations.Index{}, 1); !errors.Is(err, locations.ErrNoWaypoints) {
+		t.Errorf("expected ErrNoWaypoints, got %v", err)
+	}
+
+	w := line(10)
+	if _, err := w.Locate(locations.NewIndex(w[:5]), 1); !errors.Is(err, locations.ErrInvalidIndex) {
+		t.Errorf("expected ErrInvalidIndex, got %v", err)
+	}
+}
+
+func BenchmarkGetLocation(b *testing.B) {
+	locations.SetGeocoder(nowhere{})
+	b.Cleanup(func() {
+		locations.SetGeocoder(nil)
+	})
+
+	for _, n := range []int{10000, 100000} {
+		w := line(n)
+		idx := locations.NewIndex(w)
+		distance := idx.Total() * 0.75
+
+		b.Run(fmt.Sprintf("linear/%d", n), func(b *testing.B) {
+			for b.Loop() {
+				linearLocation(w, distance)
+			}
+		})
+
+		b.Run(fmt.Sprintf("indexed/%d", n), func(b *testing.B) {
+			for b.Loop() {
+				_, _ = w.Locate(idx, distance)
+			}
+		})
+	}
+}
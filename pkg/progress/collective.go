@@ -0,0 +1,117 @@
+package progress
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/AustinBayley/activity_tracker_api/pkg/challenges"
+	"github.com/AustinBayley/activity_tracker_api/pkg/service"
+	"github.com/AustinBayley/activity_tracker_api/pkg/targets"
+)
+
+var (
+	ErrNotCollective = errors.New("challenge is not collective")
+)
+
+// Contribution is what a single member has added to a collective challenge.
+type Contribution struct {
+	User service.ID `json:"user"`
+	// Progress is the member's own activities evaluated against the challenge target.
+	Progress targets.Progress `json:"progress"`
+	// Share is the percentage of the group's total the member contributed.
+	Share float64 `json:"share"`
+}
+
+// CollectiveRecord is the pooled progress of every member of a challenge.
+type CollectiveRecord struct {
+	Challenge service.ID       `json:"challenge"`
+	Window    targets.Window   `json:"window"`
+	Progress  targets.Progress `json:"progress"`
+	ReachedAt *time.Time       `json:"reachedAt,omitempty"`
+	Members   []Contribution   `json:"members"`
+}
+
+// Collective evaluates the union of every member's activities against the target of a collective
+// challenge, along with each member's share of it. Returns ErrNotCollective if the challenge isn't.
+func (svc *Service) Collective(ctx context.Context, challengeID service.ID) (*CollectiveRecord, error) {
+	challenge := challenges.Detail{}
+	if err := svc.challenges.Get(ctx, challengeID, &challenge); err != nil {
+		if errors.Is(err, challenges.ErrNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get challenge: %w", err)
+	}
+
+	if !challenge.Collective {
+		return nil, ErrNotCollective
+	}
+
+	opts := challenges.NewMembershipListOptions()
+	opts.SetChallenge(challengeID)
+
+	mems := []challenges.Membership{}
+	if err := svc.challenges.ListMemberships(ctx, opts, &mems); err != nil {
+		return nil, fmt.Errorf("failed to list memberships: %w", err)
+	}
+
+	progress, reached, byMember, err := svc.pooled(ctx, challenge, mems)
+	if err != nil {
+		return nil, err
+	}
+
+	record := CollectiveRecord{
+		Challenge: challenge.ID,
+		Window:    challenge.Window(challenges.Membership{}),
+		Progress:  progress,
+		ReachedAt: reached,
+		Members:   make([]Contribution, 0, len(mems)),
+	}
+
+	wCtx := targets.WithWindow(svc.withRoutes(ctx), record.Window)
+	for _, m := range mems {
+		p, err := challenge.Target.Evaluate(wCtx, byMember[m.User])
+		if err != nil {
+			return nil, fmt.Errorf("failed to evaluate contribution of %s: %w", m.User.ConvertID(), err)
+		}
+
+		record.Members = append(record.Members, Contribution{
+			User:     m.User,
+			Progress: p,
+		})
+	}
+
+	Share(record.Members)
+
+	return &record, nil
+}
+
+// Share sets the share of each contribution and orders them from largest to smallest. Where the
+// progress is Projectable a member's share is of the total amount achieved, otherwise it is of
+// the sum of their percentages.
+func Share(contributions []Contribution) {
+	amount := func(p targets.Progress) float64 {
+		if pp, ok := p.(targets.Projectable); ok {
+			return pp.Value()
+		}
+		return p.Percentage()
+	}
+
+	total := 0.0
+	for _, c := range contributions {
+		total += amount(c.Progress)
+	}
+
+	for i := range contributions {
+		contributions[i].Share = 0
+		if total > 0 {
+			contributions[i].Share = amount(contributions[i].Progress) / total * 100
+		}
+	}
+
+	sort.SliceStable(contributions, func(i, j int) bool {
+		return contributions[i].Share > contributions[j].Share
+	})
+}
@@ -0,0 +1,47 @@
+package progress_test
+
+import (
+	"testing"
+
+	"github.com/AustinBayley/activity_tracker_api/pkg/progress"
+	"github.com/AustinBayley/activity_tracker_api/pkg/targets"
+)
+
+func TestShare(t *testing.T) {
+	contributions := []progress.Contribution{
+		{User: "a", Progress: targets.DistanceTargetProgress{Percent: 10, DistanceCovered: 10}},
+		{User: "b", Progress: targets.DistanceTargetProgress{Percent: 30, DistanceCovered: 30}},
+		{User: "c", Progress: targets.DistanceTargetProgress{}},
+	}
+
+	progress.Share(contributions)
+
+	expected := []struct {
+		user  string
+		share float64
+	}{
+		{"b", 75},
+		{"a", 25},
+		{"c", 0},
+	}
+	for i, e := range expected {
+		if string(contributions[i].User) != e.user || contributions[i].Share != e.share {
+			t.Errorf("expected %s with share %v at %d, got %s with %v", e.user, e.share, i, contributions[i].User, contributions[i].Share)
+		}
+	}
+}
+
+func TestShareNoProgress(t *testing.T) {
+	contributions := []progress.Contribution{
+		{User: "a", Progress: targets.StreakTargetProgress{}},
+		{User: "b", Progress: targets.StreakTargetProgress{}},
+	}
+
+	progress.Share(contributions)
+
+	for _, c := range contributions {
+		if c.Share != 0 {
+			t.Errorf("expected no share for %s, got %v", c.User, c.Share)
+		}
+	}
+}
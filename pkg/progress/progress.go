@@ -0,0 +1,472 @@
+package progress
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/AustinBayley/activity_tracker_api/pkg/activities"
+	"github.com/AustinBayley/activity_tracker_api/pkg/challenges"
+	"github.com/AustinBayley/activity_tracker_api/pkg/routes"
+	"github.com/AustinBayley/activity_tracker_api/pkg/service"
+	"github.com/AustinBayley/activity_tracker_api/pkg/targets"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// ProjectionDays is the number of days progress is averaged over to project completion.
+	ProjectionDays = 14
+	// staleAfter is how long a record is read before being re-evaluated, as progress and
+	// projections of some targets depend on the current time.
+	staleAfter = time.Hour
+)
+
+// Service keeps the progress of every challenge member up to date, so reads don't have to
+// evaluate a member's whole activity history. Records should be refreshed whenever a user's
+// activities, a challenge or its memberships change.
+type Service struct {
+	records    *Records
+	activities *activities.Service
+	challenges *challenges.Service
+	routes     *routes.Service
+}
+
+func New(
+	records *Records,
+	activities *activities.Service,
+	challenges *challenges.Service,
+	routes *routes.Service,
+) *Service {
+	return &Service{
+		records:    records,
+		activities: activities,
+		challenges: challenges,
+		routes:     routes,
+	}
+}
+
+// Setup initializes the progress service, setting up the underlying database and collections.
+func (svc *Service) Setup(ctx context.Context) error {
+	if err := svc.records.Setup(ctx); err != nil {
+		return fmt.Errorf("failed to setup progress records: %w", err)
+	}
+	return nil
+}
+
+// withRoutes returns a copy of ctx that targets can resolve routes from the library with.
+func (svc *Service) withRoutes(ctx context.Context) context.Context {
+	return targets.WithRouteResolver(ctx, svc.routes)
+}
+
+// memberActivities returns a member's activities within the challenge window.
+func (svc *Service) memberActivities(ctx context.Context, challenge challenges.Detail, membership challenges.Membership) ([]activities.Activity, targets.Window, error) {
+	window := challenge.Window(membership)
+
+	if challenge.Target == nil {
+		return nil, window, fmt.Errorf("%w: challenge %s has no target", ErrInvalid, challenge.ID.ConvertID())
+	}
+
+	opts := activities.NewListOptions().
+		SetUser(membership.User).
+		SetFrom(window.Start).
+		SetTo(window.End)
+
+	acts := []activities.Activity{}
+	if err := svc.activities.List(ctx, *opts, &acts); err != nil {
+		return nil, window, fmt.Errorf("failed to list user activities: %w", err)
+	}
+
+	return acts, window, nil
+}
+
+// Evaluate evaluates a member's activities within the challenge window against its target.
+func (svc *Service) Evaluate(ctx context.Context, challenge challenges.Detail, membership challenges.Membership) (Record, error) {
+	acts, window, err := svc.memberActivities(ctx, challenge, membership)
+	if err != nil {
+		return Record{}, err
+	}
+
+	ctx = svc.withRoutes(ctx)
+
+	progress, reached, err := targets.ReachedAt(targets.WithWindow(ctx, window), challenge.Target, acts)
+	if err != nil {
+		return Record{}, fmt.Errorf("failed to evaluate target: %w", err)
+	}
+
+	projection, err := targets.Project(ctx, challenge.Target, acts, window, ProjectionDays, time.Now())
+	if err != nil {
+		return Record{}, fmt.Errorf("failed to project progress: %w", err)
+	}
+
+	record := Record{
+		Challenge:  challenge.ID,
+		User:       membership.User,
+		Window:     window,
+		Percent:    progress.Percentage(),
+		Progress:   progress,
+		Projection: &projection,
+	}
+	if !reached.IsZero() {
+		record.ReachedAt = &reached
+	}
+
+	return record, nil
+}
+
+// Get returns a member's progress towards a challenge, evaluating it if it hasn't been yet
+// or was last evaluated a while ago.
+func (svc *Service) Get(ctx context.Context, challengeID service.ID, userID service.ID) (*Record, error) {
+	record := Record{}
+	err := svc.records.Get(ctx, challengeID, userID, &record)
+	if err == nil && time.Since(record.Updated) < staleAfter {
+		return &record, nil
+	}
+
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return nil, fmt.Errorf("failed to get progress: %w", err)
+	}
+
+	return svc.RefreshMember(ctx, challengeID, userID)
+}
+
+// History is a member's cumulative progress towards a challenge over time.
+type History struct {
+	Interval targets.Period  `json:"interval"`
+	Window   targets.Window  `json:"window"`
+	Points   []targets.Point `json:"points"`
+}
+
+// History replays a member's activities through the challenge target, returning their
+// cumulative progress at the end of each interval.
+func (svc *Service) History(ctx context.Context, challengeID service.ID, userID service.ID, interval targets.Period, loc *time.Location) (*History, error) {
+	challenge := challenges.Detail{}
+	if err := svc.challenges.Get(ctx, challengeID, &challenge); err != nil {
+		if errors.Is(err, challenges.ErrNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get challenge: %w", err)
+	}
+
+	membership, err := svc.challenges.GetMembership(ctx, challengeID, userID)
+	if err != nil {
+		if errors.Is(err, challenges.ErrNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get membership: %w", err)
+	}
+
+	acts, window, err := svc.memberActivities(ctx, challenge, *membership)
+	if err != nil {
+		return nil, err
+	}
+
+	points, err := targets.History(svc.withRoutes(ctx), challenge.Target, acts, interval, loc, window)
+	if err != nil {
+		return nil, fmt.Errorf("failed to replay progress: %w", err)
+	}
+
+	return &History{
+		Interval: interval,
+		Window:   window,
+		Points:   points,
+	}, nil
+}
+
+// List retrieves progress records based on the given criteria, ranked by highest progress.
+// Records of the challenge or user that are missing or stale are re-evaluated first, as with Get.
+func (svc *Service) List(ctx context.Context, opts RecordListOptions, records *[]Record) error {
+	if err := svc.freshen(ctx, opts); err != nil {
+		return err
+	}
+
+	if err := svc.records.List(ctx, opts, records); err != nil {
+		return fmt.Errorf("failed to list progress: %w", err)
+	}
+	return nil
+}
+
+// freshen re-evaluates the progress of the memberships of a challenge or user that have no
+// record, or whose record was last evaluated a while ago.
+func (svc *Service) freshen(ctx context.Context, opts RecordListOptions) error {
+	if opts.Challenge == nil && opts.User == nil {
+		return nil
+	}
+
+	memOpts := challenges.NewMembershipListOptions()
+	recordOpts := NewRecordListOptions()
+	if opts.Challenge != nil {
+		memOpts.SetChallenge(*opts.Challenge)
+		recordOpts.SetChallenge(*opts.Challenge)
+	}
+	if opts.User != nil {
+		memOpts.SetUser(*opts.User)
+		recordOpts.SetUser(*opts.User)
+	}
+
+	mems := []challenges.Membership{}
+	if err := svc.challenges.ListMemberships(ctx, memOpts, &mems); err != nil {
+		return fmt.Errorf("failed to list memberships: %w", err)
+	}
+
+	records := []Record{}
+	if err := svc.records.List(ctx, recordOpts, &records); err != nil {
+		return fmt.Errorf("failed to list progress: %w", err)
+	}
+
+	type member struct{ challenge, user service.ID }
+	updated := make(map[member]time.Time, len(records))
+	for _, r := range records {
+		updated[member{r.Challenge, r.User}] = r.Updated
+	}
+
+	// Only fetch each challenge once
+	details := map[service.ID]*challenges.Detail{}
+	for _, m := range mems {
+		if t, ok := updated[member{m.Challenge, m.User}]; ok && time.Since(t) < staleAfter {
+			continue
+		}
+
+		challenge, ok := details[m.Challenge]
+		if !ok {
+			challenge = &challenges.Detail{}
+			if err := svc.challenges.Get(ctx, m.Challenge, challenge); err != nil {
+				if !errors.Is(err, challenges.ErrNotFound) {
+					return fmt.Errorf("failed to get challenge %s: %w", m.Challenge.ConvertID(), err)
+				}
+				// Memberships can outlive a challenge created by a deleted user
+				challenge = nil
+			}
+			details[m.Challenge] = challenge
+		}
+
+		if challenge == nil {
+			continue
+		}
+
+		// A stale record is still better than none, so carry on with the rest
+		if _, err := svc.refresh(ctx, *challenge, m); err != nil {
+			log.Error().
+				Err(err).
+				Str("userID", m.User.ConvertID()).
+				Str("challengeID", m.Challenge.ConvertID()).
+				Msg("failed to refresh progress")
+		}
+	}
+
+	return nil
+}
+
+// RefreshMember re-evaluates a member's progress towards a challenge. If the user is no longer
+// a member, or the challenge no longer exists, their record is removed and ErrNotFound returned.
+func (svc *Service) RefreshMember(ctx context.Context, challengeID service.ID, userID service.ID) (*Record, error) {
+	challenge := challenges.Detail{}
+	if err := svc.challenges.Get(ctx, challengeID, &challenge); err != nil {
+		if errors.Is(err, challenges.ErrNotFound) {
+			return nil, svc.forget(ctx, RecordDeleteOpts{Challenge: &challengeID})
+		}
+		return nil, fmt.Errorf("failed to get challenge: %w", err)
+	}
+
+	membership, err := svc.challenges.GetMembership(ctx, challengeID, userID)
+	if err != nil {
+		if errors.Is(err, challenges.ErrNotFound) {
+			return nil, svc.forget(ctx, RecordDeleteOpts{Challenge: &challengeID, User: &userID})
+		}
+		return nil, fmt.Errorf("failed to get membership: %w", err)
+	}
+
+	return svc.refresh(ctx, challenge, *membership)
+}
+
+// RefreshUser re-evaluates a user's progress towards every challenge they are a member of,
+// e.g. after their activities have changed. Challenges that fail are skipped, and their errors
+// returned together once the rest have been refreshed.
+func (svc *Service) RefreshUser(ctx context.Context, userID service.ID) error {
+	opts := challenges.NewMembershipListOptions()
+	opts.SetUser(userID)
+
+	mems := []challenges.Membership{}
+	if err := svc.challenges.ListMemberships(ctx, opts, &mems); err != nil {
+		return fmt.Errorf("failed to list memberships: %w", err)
+	}
+
+	// One challenge failing shouldn't stop the user's progress in the others being refreshed
+	var errs []error
+	ids := make([]service.ID, 0, len(mems))
+	for _, m := range mems {
+		challenge := challenges.Detail{}
+		if err := svc.challenges.Get(ctx, m.Challenge, &challenge); err != nil {
+			// Memberships can outlive a challenge created by a deleted user, and their records
+			// are removed below
+			if errors.Is(err, challenges.ErrNotFound) {
+				continue
+			}
+
+			log.Error().
+				Err(err).
+				Str("userID", userID.ConvertID()).
+				Str("challengeID", m.Challenge.ConvertID()).
+				Msg("failed to get challenge")
+
+			errs = append(errs, fmt.Errorf("failed to get challenge %s: %w", m.Challenge.ConvertID(), err))
+			ids = append(ids, m.Challenge)
+			continue
+		}
+
+		if _, err := svc.refresh(ctx, challenge, m); err != nil {
+			log.Error().
+				Err(err).
+				Str("userID", userID.ConvertID()).
+				Str("challengeID", m.Challenge.ConvertID()).
+				Msg("failed to refresh progress")
+
+			errs = append(errs, err)
+		}
+		ids = append(ids, m.Challenge)
+	}
+
+	// Remove records for challenges the user has left or that no longer exist
+	deleteOpts := RecordDeleteOpts{
+		User:             &userID,
+		ExceptChallenges: ids,
+	}
+	if err := svc.records.Delete(ctx, deleteOpts); err != nil {
+		errs = append(errs, fmt.Errorf("failed to delete stale progress: %w", err))
+	}
+
+	return errors.Join(errs...)
+}
+
+// RefreshChallenge re-evaluates the progress of every member of a challenge, e.g. after its
+// target has changed. If the challenge no longer exists its records are removed.
+func (svc *Service) RefreshChallenge(ctx context.Context, challengeID service.ID) error {
+	challenge := challenges.Detail{}
+	if err := svc.challenges.Get(ctx, challengeID, &challenge); err != nil {
+		if errors.Is(err, challenges.ErrNotFound) {
+			if err := svc.records.Delete(ctx, RecordDeleteOpts{Challenge: &challengeID}); err != nil {
+				return fmt.Errorf("failed to delete progress: %w", err)
+			}
+			return nil
+		}
+		return fmt.Errorf("failed to get challenge: %w", err)
+	}
+
+	opts := challenges.NewMembershipListOptions()
+	opts.SetChallenge(challengeID)
+
+	mems := []challenges.Membership{}
+	if err := svc.challenges.ListMemberships(ctx, opts, &mems); err != nil {
+		return fmt.Errorf("failed to list memberships: %w", err)
+	}
+
+	ids := make([]service.ID, 0, len(mems))
+	for _, m := range mems {
+		if _, err := svc.refresh(ctx, challenge, m); err != nil {
+			return err
+		}
+		ids = append(ids, m.User)
+	}
+
+	// Remove records for users who have left
+	deleteOpts := RecordDeleteOpts{
+		Challenge:   &challengeID,
+		ExceptUsers: ids,
+	}
+	if err := svc.records.Delete(ctx, deleteOpts); err != nil {
+		return fmt.Errorf("failed to delete stale progress: %w", err)
+	}
+
+	return nil
+}
+
+// RefreshRoute re-evaluates the progress of every challenge that references a route from the
+// library, e.g. after its waypoints have changed.
+func (svc *Service) RefreshRoute(ctx context.Context, routeID service.ID) error {
+	cs := []challenges.Detail{}
+	if err := svc.challenges.ListByRoute(ctx, routeID, &cs); err != nil {
+		return fmt.Errorf("failed to list challenges: %w", err)
+	}
+
+	for _, c := range cs {
+		if err := svc.RefreshChallenge(ctx, c.ID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Rebuild discards every progress record and re-evaluates every membership, returning the
+// number of records written. Memberships that can't be evaluated are logged and skipped, so one
+// broken challenge doesn't leave the others without progress.
+func (svc *Service) Rebuild(ctx context.Context) (int, error) {
+	if err := svc.records.Delete(ctx, RecordDeleteOpts{}); err != nil {
+		return 0, fmt.Errorf("failed to delete progress: %w", err)
+	}
+
+	mems := []challenges.Membership{}
+	if err := svc.challenges.ListMemberships(ctx, challenges.NewMembershipListOptions(), &mems); err != nil {
+		return 0, fmt.Errorf("failed to list memberships: %w", err)
+	}
+
+	// Only fetch each challenge once
+	details := map[service.ID]*challenges.Detail{}
+	count := 0
+	for _, m := range mems {
+		challenge, ok := details[m.Challenge]
+		if !ok {
+			challenge = &challenges.Detail{}
+			if err := svc.challenges.Get(ctx, m.Challenge, challenge); err != nil {
+				// Memberships can outlive a challenge created by a deleted user
+				if !errors.Is(err, challenges.ErrNotFound) {
+					log.Error().
+						Err(err).
+						Str("challengeID", m.Challenge.ConvertID()).
+						Msg("failed to get challenge")
+				}
+				challenge = nil
+			}
+			details[m.Challenge] = challenge
+		}
+
+		if challenge == nil {
+			continue
+		}
+
+		if _, err := svc.refresh(ctx, *challenge, m); err != nil {
+			log.Error().
+				Err(err).
+				Str("userID", m.User.ConvertID()).
+				Str("challengeID", m.Challenge.ConvertID()).
+				Msg("failed to refresh progress")
+			continue
+		}
+		count++
+	}
+
+	return count, nil
+}
+
+// refresh evaluates and stores a member's progress.
+func (svc *Service) refresh(ctx context.Context, challenge challenges.Detail, membership challenges.Membership) (*Record, error) {
+	record, err := svc.Evaluate(ctx, challenge, membership)
+	if err != nil {
+		return nil, fmt.Errorf("failed to evaluate progress of %s in %s: %w",
+			membership.User.ConvertID(), challenge.ID.ConvertID(), err)
+	}
+
+	if err := svc.records.Set(ctx, &record); err != nil {
+		return nil, fmt.Errorf("failed to store progress: %w", err)
+	}
+
+	return &record, nil
+}
+
+// forget removes progress records which no longer have a membership, always returning ErrNotFound.
+func (svc *Service) forget(ctx context.Context, opts RecordDeleteOpts) error {
+	if err := svc.records.Delete(ctx, opts); err != nil {
+		return fmt.Errorf("failed to delete progress: %w", err)
+	}
+	return ErrNotFound
+}
@@ -0,0 +1,267 @@
+package progress
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/AustinBayley/activity_tracker_api/pkg/service"
+	"github.com/AustinBayley/activity_tracker_api/pkg/targets"
+	"github.com/rs/zerolog/log"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+var (
+	_ targets.Progress = (*Stored)(nil)
+)
+
+// Stored is progress read back from the database. It holds the fields of whichever progress
+// type the challenge's target produced, all of which include a percent.
+type Stored bson.M
+
+func (s Stored) Percentage() float64 {
+	percent, _ := s["percent"].(float64)
+	return percent
+}
+
+// Decode decodes the stored progress into the progress type of the challenge's target.
+func (s Stored) Decode(progress targets.Progress) error {
+	b, err := bson.Marshal(s)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalid, err)
+	}
+
+	if err := bson.Unmarshal(b, progress); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalid, err)
+	}
+
+	return nil
+}
+
+// Record is the latest evaluated progress of a member towards a challenge.
+type Record struct {
+	Challenge service.ID       `json:"challenge" bson:"challenge"`
+	User      service.ID       `json:"user" bson:"user"`
+	Window    targets.Window   `json:"window" bson:"window"`
+	Percent   float64          `json:"-" bson:"percent"`
+	Progress  targets.Progress `json:"progress" bson:"progress"`
+	ReachedAt *time.Time       `json:"reachedAt,omitempty" bson:"reachedAt,omitempty"`
+	// Projection is as of when the record was last updated.
+	Projection *targets.Projection `json:"projection,omitempty" bson:"projection,omitempty"`
+	Updated    time.Time           `json:"updated" bson:"updated"`
+}
+
+type rawRecord struct {
+	Challenge  service.ID          `bson:"challenge"`
+	User       service.ID          `bson:"user"`
+	Window     targets.Window      `bson:"window"`
+	Percent    float64             `bson:"percent"`
+	Progress   bson.Raw            `bson:"progress"`
+	ReachedAt  *time.Time          `bson:"reachedAt,omitempty"`
+	Projection *targets.Projection `bson:"projection,omitempty"`
+	Updated    time.Time           `bson:"updated"`
+}
+
+func (r *Record) UnmarshalBSON(b []byte) error {
+	raw := rawRecord{}
+	if err := bson.Unmarshal(b, &raw); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalid, err)
+	}
+
+	// Decode nested documents as maps so the progress marshals to JSON as it was evaluated
+	stored := Stored{}
+	if len(raw.Progress) > 0 {
+		dec := bson.NewDecoder(bson.NewDocumentReader(bytes.NewReader(raw.Progress)))
+		dec.DefaultDocumentM()
+		if err := dec.Decode(&stored); err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalid, err)
+		}
+	}
+
+	r.Challenge = raw.Challenge
+	r.User = raw.User
+	r.Window = raw.Window
+	r.Percent = raw.Percent
+	r.Progress = stored
+	r.ReachedAt = raw.ReachedAt
+	r.Projection = raw.Projection
+	r.Updated = raw.Updated
+	return nil
+}
+
+// Records wraps a MongoDB collection of evaluated progress.
+type Records struct {
+	*mongo.Collection
+}
+
+// NewRecords creates a new Records instance with the provided MongoDB collection.
+func NewRecords(c *mongo.Collection) *Records {
+	return &Records{c}
+}
+
+// Setup initializes the progress collection in the database.
+func (svc *Records) Setup(ctx context.Context) error {
+	if err := svc.Database().CreateCollection(ctx, svc.Name()); err != nil {
+		return fmt.Errorf("failed to create progress collection: %w", err)
+	}
+
+	_, err := svc.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "challenge", Value: 1}, {Key: "user", Value: 1}},
+			Options: options.Index().SetUnique(true).SetName("challenge_user_unique_index"),
+		},
+		{
+			Keys:    bson.D{{Key: "challenge", Value: 1}, {Key: "percent", Value: -1}, {Key: "reachedAt", Value: 1}},
+			Options: options.Index().SetName("challenge_ranking_index"),
+		},
+	})
+
+	if err != nil {
+		log.Error().
+			Err(err).
+			Msg("failed to create indexes for progress")
+	}
+
+	return nil
+}
+
+// Set creates or replaces the progress record for a member of a challenge.
+func (svc *Records) Set(ctx context.Context, record *Record) error {
+	record.Updated = time.Now()
+	if record.Progress != nil {
+		record.Percent = record.Progress.Percentage()
+	}
+
+	filter := bson.D{
+		{Key: "challenge", Value: record.Challenge.ConvertID()},
+		{Key: "user", Value: record.User.ConvertID()},
+	}
+
+	opts := options.Replace().SetUpsert(true)
+	if _, err := svc.ReplaceOne(ctx, filter, record, opts); err != nil {
+		return fmt.Errorf("%w: %w", ErrUnknown, err)
+	}
+
+	return nil
+}
+
+// Get retrieves the progress record for a member of a challenge.
+func (svc *Records) Get(ctx context.Context, challengeID service.ID, userID service.ID, record *Record) error {
+	filter := bson.D{
+		{Key: "challenge", Value: challengeID.ConvertID()},
+		{Key: "user", Value: userID.ConvertID()},
+	}
+
+	if err := svc.FindOne(ctx, filter).Decode(record); err != nil {
+		switch {
+		case errors.Is(err, mongo.ErrNoDocuments):
+			return ErrNotFound
+		}
+		return fmt.Errorf("%w: %w", ErrUnknown, err)
+	}
+
+	return nil
+}
+
+type RecordListOptions struct {
+	Limit int64
+	Skip  int64
+
+	Challenge *service.ID
+	User      *service.ID
+}
+
+func NewRecordListOptions() RecordListOptions {
+	return RecordListOptions{}
+}
+
+func (opts *RecordListOptions) SetLimit(limit int64) *RecordListOptions {
+	opts.Limit = limit
+	return opts
+}
+
+func (opts *RecordListOptions) SetSkip(skip int64) *RecordListOptions {
+	opts.Skip = skip
+	return opts
+}
+
+func (opts *RecordListOptions) SetChallenge(id service.ID) *RecordListOptions {
+	opts.Challenge = &id
+	return opts
+}
+
+func (opts *RecordListOptions) SetUser(id service.ID) *RecordListOptions {
+	opts.User = &id
+	return opts
+}
+
+// List retrieves progress records based on the given criteria, ranked by highest progress
+// and then earliest to reach it.
+func (svc *Records) List(ctx context.Context, opts RecordListOptions, records *[]Record) error {
+	options := options.Find().
+		SetSort(bson.D{{Key: "percent", Value: -1}, {Key: "reachedAt", Value: 1}})
+
+	if opts.Limit > 0 {
+		options = options.SetLimit(opts.Limit)
+	}
+
+	if opts.Skip > 0 {
+		options = options.SetSkip(opts.Skip)
+	}
+
+	filter := bson.D{}
+	if opts.Challenge != nil {
+		filter = append(filter, bson.E{Key: "challenge", Value: opts.Challenge.ConvertID()})
+	}
+	if opts.User != nil {
+		filter = append(filter, bson.E{Key: "user", Value: opts.User.ConvertID()})
+	}
+
+	cursor, err := svc.Find(ctx, filter, options)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrUnknown, err)
+	}
+
+	if err := cursor.All(ctx, records); err != nil {
+		return fmt.Errorf("%w: %w", ErrUnknown, err)
+	}
+
+	return nil
+}
+
+type RecordDeleteOpts struct {
+	Challenge *service.ID
+	User      *service.ID
+	// ExceptChallenges keeps records for these challenges.
+	ExceptChallenges []service.ID
+	// ExceptUsers keeps records for these users.
+	ExceptUsers []service.ID
+}
+
+// Delete removes progress records based on the provided criteria.
+// Supplying no criteria removes every record.
+func (svc *Records) Delete(ctx context.Context, opts RecordDeleteOpts) error {
+	filter := bson.D{}
+	if opts.Challenge != nil {
+		filter = append(filter, bson.E{Key: "challenge", Value: opts.Challenge.ConvertID()})
+	}
+	if opts.User != nil {
+		filter = append(filter, bson.E{Key: "user", Value: opts.User.ConvertID()})
+	}
+	if len(opts.ExceptChallenges) > 0 {
+		filter = append(filter, bson.E{Key: "challenge", Value: bson.D{{Key: "$nin", Value: opts.ExceptChallenges}}})
+	}
+	if len(opts.ExceptUsers) > 0 {
+		filter = append(filter, bson.E{Key: "user", Value: bson.D{{Key: "$nin", Value: opts.ExceptUsers}}})
+	}
+
+	if _, err := svc.DeleteMany(ctx, filter); err != nil {
+		return fmt.Errorf("%w: %w", ErrUnknown, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,62 @@
+package progress_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/AustinBayley/activity_tracker_api/pkg/progress"
+	"github.com/AustinBayley/activity_tracker_api/pkg/targets"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func TestRecordUnmarshal(t *testing.T) {
+	start := time.Date(2025, time.October, 6, 0, 0, 0, 0, time.UTC)
+	record := progress.Record{
+		Challenge: "challenge",
+		User:      "user",
+		Progress: targets.FrequencyTargetProgress{
+			Percent:       50,
+			WeeksPassed:   1,
+			WeeksRequired: 2,
+			Weeks: []targets.WeekResult{
+				{Start: start, Sessions: 3, Passed: true},
+			},
+		},
+	}
+
+	data, err := bson.Marshal(record)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	var got progress.Record
+	if err := bson.Unmarshal(data, &got); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if got.Progress.Percentage() != 50 {
+		t.Errorf("expected percentage 50, got %f", got.Progress.Percentage())
+	}
+
+	// Stored progress should marshal to the same JSON as the evaluated progress
+	expected, err := json.Marshal(record.Progress)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	actual, err := json.Marshal(got.Progress)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	var expectedMap, actualMap map[string]interface{}
+	_ = json.Unmarshal(expected, &expectedMap)
+	_ = json.Unmarshal(actual, &actualMap)
+
+	e, _ := json.Marshal(expectedMap)
+	a, _ := json.Marshal(actualMap)
+	if string(e) != string(a) {
+		t.Errorf("expected %s, got %s", e, a)
+	}
+}
@@ -0,0 +1,159 @@
+package progress
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/AustinBayley/activity_tracker_api/pkg/activities"
+	"github.com/AustinBayley/activity_tracker_api/pkg/challenges"
+	"github.com/AustinBayley/activity_tracker_api/pkg/service"
+	"github.com/AustinBayley/activity_tracker_api/pkg/targets"
+)
+
+// TeamRecord is the pooled progress of a team's members towards a challenge.
+type TeamRecord struct {
+	Team      challenges.Team  `json:"team"`
+	Members   []service.ID     `json:"members"`
+	Window    targets.Window   `json:"window"`
+	Progress  targets.Progress `json:"progress"`
+	ReachedAt *time.Time       `json:"reachedAt,omitempty"`
+}
+
+// EvaluateTeam evaluates the union of a team's members' activities against the challenge target.
+func (svc *Service) EvaluateTeam(ctx context.Context, challenge challenges.Detail, team challenges.Team) (TeamRecord, error) {
+	opts := challenges.NewMembershipListOptions()
+	opts.SetChallenge(challenge.ID).
+		SetTeam(team.ID)
+
+	mems := []challenges.Membership{}
+	if err := svc.challenges.ListMemberships(ctx, opts, &mems); err != nil {
+		return TeamRecord{}, fmt.Errorf("failed to list team memberships: %w", err)
+	}
+
+	record := TeamRecord{
+		Team:    team,
+		Members: make([]service.ID, 0, len(mems)),
+		Window:  challenge.Window(challenges.Membership{}),
+	}
+
+	for _, m := range mems {
+		record.Members = append(record.Members, m.User)
+	}
+
+	progress, reached, _, err := svc.pooled(ctx, challenge, mems)
+	if err != nil {
+		return TeamRecord{}, err
+	}
+
+	record.Progress = progress
+	record.ReachedAt = reached
+
+	return record, nil
+}
+
+// pooled evaluates the union of members' activities against the challenge target. Each member's
+// activities are limited to their own window, so the group is only credited with what its members
+// would be credited with individually. The activities counted for each member are also returned.
+func (svc *Service) pooled(ctx context.Context, challenge challenges.Detail, mems []challenges.Membership) (targets.Progress, *time.Time, map[service.ID][]activities.Activity, error) {
+	if challenge.Target == nil {
+		return nil, nil, nil, fmt.Errorf("%w: challenge %s has no target", ErrInvalid, challenge.ID.ConvertID())
+	}
+
+	acts := []activities.Activity{}
+	byMember := make(map[service.ID][]activities.Activity, len(mems))
+	for _, m := range mems {
+		memberActs, _, err := svc.memberActivities(ctx, challenge, m)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		acts = append(acts, memberActs...)
+		byMember[m.User] = memberActs
+	}
+
+	window := challenge.Window(challenges.Membership{})
+	progress, reached, err := targets.ReachedAt(targets.WithWindow(svc.withRoutes(ctx), window), challenge.Target, acts)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to evaluate target: %w", err)
+	}
+
+	if reached.IsZero() {
+		return progress, nil, byMember, nil
+	}
+	return progress, &reached, byMember, nil
+}
+
+// GetTeam returns the pooled progress of a team towards a challenge.
+func (svc *Service) GetTeam(ctx context.Context, challengeID service.ID, teamID service.ID) (*TeamRecord, error) {
+	challenge := challenges.Detail{}
+	if err := svc.challenges.Get(ctx, challengeID, &challenge); err != nil {
+		if errors.Is(err, challenges.ErrNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get challenge: %w", err)
+	}
+
+	team := challenges.Team{}
+	if err := svc.challenges.GetTeam(ctx, challengeID, teamID, &team); err != nil {
+		if errors.Is(err, challenges.ErrNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get team: %w", err)
+	}
+
+	record, err := svc.EvaluateTeam(ctx, challenge, team)
+	if err != nil {
+		return nil, err
+	}
+
+	return &record, nil
+}
+
+// ListTeams returns the pooled progress of every team in a challenge, ranked by highest progress
+// and then by who reached it first.
+func (svc *Service) ListTeams(ctx context.Context, challengeID service.ID) ([]TeamRecord, error) {
+	challenge := challenges.Detail{}
+	if err := svc.challenges.Get(ctx, challengeID, &challenge); err != nil {
+		if errors.Is(err, challenges.ErrNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get challenge: %w", err)
+	}
+
+	opts := challenges.NewTeamListOptions()
+	opts.SetChallenge(challengeID)
+
+	teams := []challenges.Team{}
+	if err := svc.challenges.ListTeams(ctx, opts, &teams); err != nil {
+		return nil, fmt.Errorf("failed to list teams: %w", err)
+	}
+
+	records := make([]TeamRecord, 0, len(teams))
+	for _, team := range teams {
+		record, err := svc.EvaluateTeam(ctx, challenge, team)
+		if err != nil {
+			return nil, fmt.Errorf("failed to evaluate team %s: %w", team.ID.ConvertID(), err)
+		}
+		records = append(records, record)
+	}
+
+	sort.SliceStable(records, func(i, j int) bool {
+		pi, pj := records[i].Progress.Percentage(), records[j].Progress.Percentage()
+		if pi != pj {
+			return pi > pj
+		}
+
+		ri, rj := records[i].ReachedAt, records[j].ReachedAt
+		switch {
+		case ri == nil:
+			return false
+		case rj == nil:
+			return true
+		}
+		return ri.Before(*rj)
+	})
+
+	return records, nil
+}
@@ -0,0 +1,317 @@
+package routes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/AustinBayley/activity_tracker_api/pkg/service"
+	"github.com/AustinBayley/activity_tracker_api/pkg/targets"
+	"github.com/AustinBayley/activity_tracker_api/pkg/validate"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+var (
+	ErrAlreadyExists = errors.New("route already exists")
+	ErrNotFound      = errors.New("route not found")
+	ErrUnknown       = errors.New("unknown error")
+	ErrInvalid       = errors.New("invalid")
+	ErrValidation    = errors.New("validation error")
+)
+
+var (
+	_ targets.RouteResolver = (*Service)(nil)
+)
+
+type Visibility string
+
+const (
+	// Public routes can be seen and used in challenges by anyone.
+	Public Visibility = "public"
+	// Private routes can only be seen and used by their owner.
+	Private Visibility = "private"
+)
+
+// Route is a route kept in the library, so it can be shared by many challenges.
+type Route struct {
+	ID          service.ID `json:"id" bson:"_id"`
+	Name        string     `json:"name" bson:"name" validate:"required"`
+	Description string     `json:"description" bson:"description"`
+	Owner       service.ID `json:"owner" bson:"owner" validate:"required"`
+	Visibility  Visibility `json:"visibility" bson:"visibility" validate:"required,oneof=public private"`
+	// Route holds the waypoints, and is simplified and indexed when saved.
+	Route targets.Route `json:"route" bson:"route"`
+	// TotalDistance is the length of the route in km, calculated when saved.
+	TotalDistance float64   `json:"totalDistance" bson:"totalDistance"`
+	CreatedDate   time.Time `json:"createdDate" bson:"createdDate" validate:"required"`
+}
+
+// VisibleTo reports whether the user may see the route.
+func (r Route) VisibleTo(userID service.ID) bool {
+	return r.Visibility == Public || r.Owner == userID
+}
+
+// prepare simplifies and indexes the waypoints before the route is saved.
+func (r *Route) prepare() error {
+	if len(r.Route.Waypoints) < 2 {
+		return fmt.Errorf("%w: a route needs at least 2 waypoints", ErrValidation)
+	}
+
+	for _, w := range r.Route.Waypoints {
+		if !w.LatLng.Valid() {
+			return fmt.Errorf("%w: waypoint %v out of range", ErrValidation, w.LatLng)
+		}
+	}
+
+	if err := r.Route.Simplify(); err != nil {
+		return fmt.Errorf("%w: %w", ErrValidation, err)
+	}
+	r.TotalDistance = r.Route.Distance()
+
+	return nil
+}
+
+type Service struct {
+	*mongo.Collection
+}
+
+func New(c *mongo.Collection) *Service {
+	return &Service{c}
+}
+
+// Setup initializes the route service, setting up the underlying database and collections.
+func (svc *Service) Setup(ctx context.Context) error {
+	if err := svc.Database().CreateCollection(ctx, svc.Name()); err != nil {
+		return fmt.Errorf("failed to create route collection: %w", err)
+	}
+
+	_, err := svc.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "owner", Value: 1}}},
+		{Keys: bson.D{{Key: "visibility", Value: 1}}},
+		{Keys: bson.D{{Key: "route.cells", Value: 1}}},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create route indexes: %w", err)
+	}
+
+	if err := svc.indexCells(ctx); err != nil {
+		return fmt.Errorf("failed to index routes: %w", err)
+	}
+
+	return nil
+}
+
+// indexCells sets the cells of routes saved before routes were indexed by location.
+func (svc *Service) indexCells(ctx context.Context) error {
+	cursor, err := svc.Find(ctx, bson.D{
+		{Key: "route.cells", Value: bson.D{{Key: "$exists", Value: false}}},
+		{Key: "route.waypoints.0", Value: bson.D{{Key: "$exists", Value: true}}},
+	}, options.Find().SetProjection(bson.D{{Key: "route.waypoints", Value: 1}}))
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		route := struct {
+			ID    service.ID    `bson:"_id"`
+			Route targets.Route `bson:"route"`
+		}{}
+		if err := cursor.Decode(&route); err != nil {
+			return err
+		}
+
+		cells, err := route.Route.Waypoints.Cells()
+		if err != nil {
+			return fmt.Errorf("route %s: %w", route.ID.ConvertID(), err)
+		}
+
+		if _, err := svc.UpdateOne(ctx,
+			bson.D{{Key: "_id", Value: route.ID.ConvertID()}},
+			bson.D{{Key: "$set", Value: bson.D{{Key: "route.cells", Value: cells}}}},
+		); err != nil {
+			return err
+		}
+	}
+
+	return cursor.Err()
+}
+
+// Create adds a new route to the database.
+func (svc *Service) Create(ctx context.Context, route *Route) (service.ID, error) {
+	route.ID = service.NewID()
+	route.CreatedDate = time.Now()
+
+	if err := route.prepare(); err != nil {
+		return "", err
+	}
+
+	if err := validate.Struct(route); err != nil {
+		return "", fmt.Errorf("%w: %w", ErrValidation, err)
+	}
+
+	res, err := svc.InsertOne(ctx, route)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return "", ErrAlreadyExists
+		}
+		return "", fmt.Errorf("%w: %w", ErrUnknown, err)
+	}
+
+	return service.ID(res.InsertedID.(string)), nil
+}
+
+// Get retrieves a route by its ID from the database.
+func (svc *Service) Get(ctx context.Context, id service.ID, route interface{}) error {
+	if err := svc.
+		FindOne(ctx, bson.D{{Key: "_id", Value: id.ConvertID()}}).
+		Decode(route); err != nil {
+		switch {
+		case errors.Is(err, mongo.ErrNoDocuments):
+			return ErrNotFound
+		}
+		return fmt.Errorf("%w: %w", ErrUnknown, err)
+	}
+
+	return nil
+}
+
+// ResolveRoute gets the waypoints of a route, so challenges can reference it.
+func (svc *Service) ResolveRoute(ctx context.Context, id service.ID) (*targets.Route, error) {
+	route := Route{}
+	if err := svc.Get(ctx, id, &route); err != nil {
+		return nil, err
+	}
+
+	return &route.Route, nil
+}
+
+type ListOptions struct {
+	Limit int64
+	Skip  int64
+
+	Owner *service.ID
+	// VisibleTo limits routes to those that are public or owned by the given user.
+	VisibleTo *service.ID
+	// Cells limits routes to those passing through any of the H3 cells.
+	Cells []string
+}
+
+func NewListOptions() *ListOptions {
+	return &ListOptions{}
+}
+
+func (opts *ListOptions) SetLimit(limit int64) *ListOptions {
+	opts.Limit = limit
+	return opts
+}
+
+func (opts *ListOptions) SetSkip(skip int64) *ListOptions {
+	opts.Skip = skip
+	return opts
+}
+
+func (opts *ListOptions) SetOwner(id service.ID) *ListOptions {
+	opts.Owner = &id
+	return opts
+}
+
+func (opts *ListOptions) SetVisibleTo(id service.ID) *ListOptions {
+	opts.VisibleTo = &id
+	return opts
+}
+
+func (opts *ListOptions) SetCells(cells []string) *ListOptions {
+	opts.Cells = cells
+	return opts
+}
+
+// List retrieves routes based on the given criteria.
+func (svc *Service) List(ctx context.Context, opts ListOptions, routes interface{}) error {
+	options := options.Find().
+		SetSort(bson.D{{Key: "name", Value: 1}}).
+		// Waypoints aren't needed to list routes and can be large
+		SetProjection(bson.D{
+			{Key: "route.waypoints", Value: 0},
+			{Key: "route.distances", Value: 0},
+			{Key: "route.cells", Value: 0},
+		})
+
+	if opts.Limit > 0 {
+		options = options.SetLimit(opts.Limit)
+	}
+
+	if opts.Skip > 0 {
+		options = options.SetSkip(opts.Skip)
+	}
+
+	filter := bson.D{}
+	if opts.Owner != nil {
+		filter = append(filter, bson.E{Key: "owner", Value: opts.Owner.ConvertID()})
+	}
+
+	if opts.VisibleTo != nil {
+		filter = append(filter, bson.E{Key: "$or", Value: bson.A{
+			bson.D{{Key: "visibility", Value: Public}},
+			bson.D{{Key: "owner", Value: opts.VisibleTo.ConvertID()}},
+		}})
+	}
+
+	if opts.Cells != nil {
+		filter = append(filter, bson.E{Key: "route.cells", Value: bson.D{{Key: "$in", Value: opts.Cells}}})
+	}
+
+	cursor, err := svc.Find(ctx, filter, options)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrUnknown, err)
+	}
+
+	if err := cursor.All(ctx, routes); err != nil {
+		return fmt.Errorf("%w: %w", ErrUnknown, err)
+	}
+
+	return nil
+}
+
+// Update replaces a route in the database.
+func (svc *Service) Update(ctx context.Context, route *Route) error {
+	if err := route.prepare(); err != nil {
+		return err
+	}
+
+	if err := validate.Struct(route); err != nil {
+		return fmt.Errorf("%w: %w", ErrValidation, err)
+	}
+
+	res, err := svc.ReplaceOne(
+		ctx,
+		bson.D{{Key: "_id", Value: route.ID.ConvertID()}},
+		route,
+	)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrUnknown, err)
+	}
+
+	if res.MatchedCount != 1 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// Delete removes a route from the database by its ID.
+func (svc *Service) Delete(ctx context.Context, id service.ID) error {
+	res, err := svc.DeleteOne(ctx, bson.D{{Key: "_id", Value: id.ConvertID()}})
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrUnknown, err)
+	}
+
+	if res.DeletedCount != 1 {
+		return ErrNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,210 @@
+// Package svg renders challenge progress as standalone SVG images, so it can be embedded in
+// pages and messages without a map tile server.
+package svg
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/AustinBayley/activity_tracker_api/pkg/locations"
+)
+
+const (
+	// ContentType is the media type of SVG images.
+	ContentType = "image/svg+xml"
+	// MinMapWidth and MinMapHeight are the smallest maps with room to draw the route inside the
+	// padding and title.
+	MinMapWidth  = 2*mapPadding + 1
+	MinMapHeight = 2*mapPadding + mapTitleHeight + 1
+
+	routeColour    = "#b0b7c3"
+	coveredColour  = "#2563eb"
+	markerColour   = "#dc2626"
+	textColour     = "#111827"
+	labelColour    = "#4b5563"
+	badgeTrack     = "#e5e7eb"
+	badgeHeight    = 20
+	badgeBarWidth  = 100
+	charWidth      = 6.5
+	mapPadding     = 20
+	mapTitleHeight = 24
+)
+
+// escape escapes text for use in an SVG document.
+func escape(s string) string {
+	b := strings.Builder{}
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+// Badge renders a compact progress bar labelled with the given text and percentage.
+func Badge(label string, percent float64) []byte {
+	percent = math.Max(0, math.Min(percent, 100))
+	value := fmt.Sprintf("%.0f%%", math.Floor(percent))
+
+	labelWidth := math.Ceil(float64(len([]rune(label)))*charWidth) + 10
+	valueWidth := math.Ceil(float64(len(value))*charWidth) + 10
+	width := labelWidth + badgeBarWidth + valueWidth
+
+	b := bytes.Buffer{}
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%.0f" height="%d" viewBox="0 0 %.0f %d" role="img" aria-label="%s: %s">`,
+		width, badgeHeight, width, badgeHeight, escape(label), value)
+	fmt.Fprintf(&b, `<title>%s: %s</title>`, escape(label), value)
+	fmt.Fprintf(&b, `<rect width="%.0f" height="%d" rx="3" fill="#fff" stroke="%s"/>`, width, badgeHeight, badgeTrack)
+	fmt.Fprintf(&b, `<g font-family="Verdana,DejaVu Sans,sans-serif" font-size="11" fill="%s">`, textColour)
+	fmt.Fprintf(&b, `<text x="5" y="14">%s</text>`, escape(label))
+	fmt.Fprintf(&b, `<text x="%.0f" y="14">%s</text>`, labelWidth+badgeBarWidth+5, value)
+	b.WriteString(`</g>`)
+	fmt.Fprintf(&b, `<rect x="%.0f" y="6" width="%d" height="8" rx="4" fill="%s"/>`, labelWidth, badgeBarWidth, badgeTrack)
+	fmt.Fprintf(&b, `<rect x="%.0f" y="6" width="%.1f" height="8" rx="4" fill="%s"/>`, labelWidth, percent/100*badgeBarWidth, coveredColour)
+	b.WriteString(`</svg>`)
+
+	return b.Bytes()
+}
+
+// MapOptions describes what to draw on a route map.
+type MapOptions struct {
+	Width  int
+	Height int
+	Title  string
+	// Covered is the distance in km along the route that is highlighted.
+	Covered float64
+	// Position is where the marker is drawn, if set.
+	Position *locations.LatLng
+}
+
+// projection maps coordinates onto the image with a Web Mercator projection, scaled to fit the
+// bounds of the route.
+type projection struct {
+	minX, maxY, scale, offsetX, offsetY float64
+}
+
+func mercator(l locations.LatLng) (float64, float64) {
+	lat := math.Max(-85, math.Min(l.Lat, 85)) * math.Pi / 180
+	return l.Lng * math.Pi / 180, math.Log(math.Tan(math.Pi/4 + lat/2))
+}
+
+func newProjection(points []locations.LatLng, width float64, height float64) projection {
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for _, p := range points {
+		x, y := mercator(p)
+		minX, maxX = math.Min(minX, x), math.Max(maxX, x)
+		minY, maxY = math.Min(minY, y), math.Max(maxY, y)
+	}
+
+	spanX, spanY := maxX-minX, maxY-minY
+	scale := math.Inf(1)
+	if spanX > 0 {
+		scale = width / spanX
+	}
+	if spanY > 0 {
+		scale = math.Min(scale, height/spanY)
+	}
+	if math.IsInf(scale, 1) {
+		scale = 1
+	}
+
+	// Centre the route in whichever dimension it doesn't fill
+	return projection{
+		minX:    minX,
+		maxY:    maxY,
+		scale:   scale,
+		offsetX: (width - spanX*scale) / 2,
+		offsetY: (height - spanY*scale) / 2,
+	}
+}
+
+func (p projection) point(l locations.LatLng) (float64, float64) {
+	x, y := mercator(l)
+	return mapPadding + p.offsetX + (x-p.minX)*p.scale, mapPadding + mapTitleHeight + p.offsetY + (p.maxY-y)*p.scale
+}
+
+func (p projection) polyline(points []locations.LatLng) string {
+	b := strings.Builder{}
+	for i, l := range points {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		x, y := p.point(l)
+		fmt.Fprintf(&b, "%.1f,%.1f", x, y)
+	}
+	return b.String()
+}
+
+// Map renders the route with the covered distance highlighted, its checkpoints and a marker at
+// the position. The index must be of the waypoints.
+func Map(w locations.Waypoints, idx locations.Index, opts MapOptions) []byte {
+	width, height := float64(opts.Width), float64(opts.Height)
+
+	route := make([]locations.LatLng, 0, len(w))
+	for _, waypoint := range w {
+		route = append(route, waypoint.LatLng)
+	}
+
+	// The covered part of the route runs up to the last waypoint passed, then on to the position
+	covered := []locations.LatLng{}
+	if len(route) > 0 && opts.Covered > 0 {
+		passed := sort.SearchFloat64s(idx, opts.Covered)
+		covered = append(covered, route[:min(passed, len(route))]...)
+		if opts.Position != nil && passed < len(route) {
+			covered = append(covered, *opts.Position)
+		}
+	}
+
+	bounds := route
+	if opts.Position != nil {
+		bounds = append(bounds[:len(bounds):len(bounds)], *opts.Position)
+	}
+	// Maps smaller than the minimum draw the route at a point rather than upside down
+	proj := newProjection(bounds, math.Max(0, width-2*mapPadding), math.Max(0, height-2*mapPadding-mapTitleHeight))
+
+	b := bytes.Buffer{}
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" role="img" aria-label="%s">`,
+		opts.Width, opts.Height, opts.Width, opts.Height, escape(opts.Title))
+	fmt.Fprintf(&b, `<title>%s</title>`, escape(opts.Title))
+	fmt.Fprintf(&b, `<rect width="%d" height="%d" fill="#fff"/>`, opts.Width, opts.Height)
+	fmt.Fprintf(&b, `<text x="%d" y="%d" font-family="Verdana,DejaVu Sans,sans-serif" font-size="14" fill="%s">%s</text>`,
+		mapPadding, mapPadding+4, textColour, escape(opts.Title))
+
+	if len(route) > 1 {
+		fmt.Fprintf(&b, `<polyline points="%s" fill="none" stroke="%s" stroke-width="3" stroke-linejoin="round" stroke-linecap="round"/>`,
+			proj.polyline(route), routeColour)
+	}
+
+	if len(covered) > 1 {
+		fmt.Fprintf(&b, `<polyline points="%s" fill="none" stroke="%s" stroke-width="4" stroke-linejoin="round" stroke-linecap="round"/>`,
+			proj.polyline(covered), coveredColour)
+	}
+
+	for i, waypoint := range w {
+		if !waypoint.Checkpoint {
+			continue
+		}
+
+		colour := routeColour
+		if idx[i] <= opts.Covered {
+			colour = coveredColour
+		}
+
+		x, y := proj.point(waypoint.LatLng)
+		fmt.Fprintf(&b, `<circle cx="%.1f" cy="%.1f" r="4" fill="#fff" stroke="%s" stroke-width="2"><title>%s</title></circle>`,
+			x, y, colour, escape(waypoint.Name))
+	}
+
+	if opts.Position != nil {
+		x, y := proj.point(*opts.Position)
+		fmt.Fprintf(&b, `<circle cx="%.1f" cy="%.1f" r="6" fill="%s" stroke="#fff" stroke-width="2"/>`, x, y, markerColour)
+	}
+
+	fmt.Fprintf(&b, `<text x="%d" y="%d" font-family="Verdana,DejaVu Sans,sans-serif" font-size="10" fill="%s">%.1f of %.1f km</text>`,
+		mapPadding, opts.Height-6, labelColour, math.Min(opts.Covered, idx.Total()), idx.Total())
+
+	b.WriteString(`</svg>`)
+
+	return b.Bytes()
+}
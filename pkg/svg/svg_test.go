@@ -0,0 +1,105 @@
+package svg_test
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/AustinBayley/activity_tracker_api/pkg/locations"
+	"github.com/AustinBayley/activity_tracker_api/pkg/svg"
+)
+
+// elements parses the document, returning the number of each element it contains.
+func elements(t *testing.T, doc []byte) map[string]int {
+	t.Helper()
+
+	counts := map[string]int{}
+	dec := xml.NewDecoder(bytes.NewReader(doc))
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return counts
+		}
+		if err != nil {
+			t.Fatalf("expected valid svg, got %v in %s", err, doc)
+		}
+
+		if start, ok := tok.(xml.StartElement); ok {
+			counts[start.Name.Local]++
+		}
+	}
+}
+
+func TestBadge(t *testing.T) {
+	doc := svg.Badge("Run & <Ride>", 42.7)
+
+	counts := elements(t, doc)
+	if counts["svg"] != 1 || counts["rect"] != 3 {
+		t.Errorf("unexpected elements %v", counts)
+	}
+
+	if !strings.Contains(string(doc), "42%") || !strings.Contains(string(doc), "Run &amp; &lt;Ride&gt;") {
+		t.Errorf("expected escaped label and percentage, got %s", doc)
+	}
+
+	if !strings.Contains(string(svg.Badge("Done", 150)), "100%") {
+		t.Error("expected percentage to be capped at 100")
+	}
+}
+
+func TestMap(t *testing.T) {
+	w := locations.Waypoints{
+		{LatLng: locations.LatLng{Lat: 50.07, Lng: -5.71}, Name: "Land's End", Checkpoint: true},
+		{LatLng: locations.LatLng{Lat: 51.45, Lng: -2.59}},
+		{LatLng: locations.LatLng{Lat: 55.95, Lng: -3.19}, Name: "Edinburgh", Checkpoint: true},
+		{LatLng: locations.LatLng{Lat: 58.64, Lng: -3.07}, Name: "John o' Groats", Checkpoint: true},
+	}
+	idx := locations.NewIndex(w)
+	position := locations.LatLng{Lat: 53, Lng: -2.8}
+
+	doc := svg.Map(w, idx, svg.MapOptions{
+		Width:    600,
+		Height:   400,
+		Title:    "LEJOG",
+		Covered:  idx[1] + 100,
+		Position: &position,
+	})
+
+	counts := elements(t, doc)
+	if counts["polyline"] != 2 {
+		t.Errorf("expected route and covered polylines, got %v", counts)
+	}
+
+	// A circle for each checkpoint and the marker
+	if counts["circle"] != 4 {
+		t.Errorf("expected 4 circles, got %v", counts)
+	}
+}
+
+func TestMapNotStarted(t *testing.T) {
+	w := locations.Waypoints{
+		{LatLng: locations.LatLng{Lat: 0, Lng: 0}},
+		{LatLng: locations.LatLng{Lat: 0, Lng: 1}},
+	}
+
+	counts := elements(t, svg.Map(w, locations.NewIndex(w), svg.MapOptions{Width: 300, Height: 200}))
+	if counts["polyline"] != 1 || counts["circle"] != 0 {
+		t.Errorf("expected only the route, got %v", counts)
+	}
+}
+
+func TestMapTooSmall(t *testing.T) {
+	w := locations.Waypoints{
+		{LatLng: locations.LatLng{Lat: 0, Lng: 0}},
+		{LatLng: locations.LatLng{Lat: 1, Lng: 1}},
+	}
+
+	doc := string(svg.Map(w, locations.NewIndex(w), svg.MapOptions{Width: 10, Height: 10}))
+	points := doc[strings.Index(doc, `points="`):]
+	points = points[:strings.Index(points, `" `)]
+	if strings.Contains(points, "-") {
+		t.Errorf("expected the route not to be drawn flipped, got %s", points)
+	}
+}
@@ -0,0 +1,116 @@
+package targets
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/AustinBayley/activity_tracker_api/pkg/activities"
+)
+
+var (
+	_ Target   = (*CompositeTarget)(nil)
+	_ Progress = (*CompositeTargetProgress)(nil)
+)
+
+const (
+	CompositeTargetType TargetType = "compositeTarget"
+)
+
+// CompositeMode is how the progress of a composite target's children is combined.
+type CompositeMode string
+
+const (
+	// CompositeAll requires every child to be complete, taking the lowest percentage.
+	CompositeAll CompositeMode = "all"
+	// CompositeAny requires one child to be complete, taking the highest percentage.
+	CompositeAny CompositeMode = "any"
+	// CompositeWeighted takes the weighted average of the children's percentages.
+	CompositeWeighted CompositeMode = "weighted"
+)
+
+// CompositeChild is a target within a composite target. Weight is only used in weighted mode
+// and defaults to 1.
+type CompositeChild struct {
+	Target RawTarget `json:"target" bson:"target"`
+	Weight float64   `json:"weight,omitempty" bson:"weight,omitempty" validate:"gte=0"`
+}
+
+type CompositeChildProgress struct {
+	Type     TargetType `json:"type" bson:"type"`
+	Weight   float64    `json:"weight" bson:"weight"`
+	Progress Progress   `json:"progress" bson:"progress"`
+}
+
+type CompositeTargetProgress struct {
+	Percent  float64                  `json:"percent" bson:"percent"`
+	Mode     CompositeMode            `json:"mode" bson:"mode"`
+	Children []CompositeChildProgress `json:"children" bson:"children"`
+}
+
+func (c CompositeTargetProgress) Percentage() float64 {
+	return c.Percent
+}
+
+// CompositeTarget combines several child targets, e.g. swim 5 km and cycle 200 km and run 40 km.
+type CompositeTarget struct {
+	BaseTarget `bson:",inline"`
+	Mode       CompositeMode    `json:"mode" bson:"mode" validate:"oneof=all any weighted"`
+	Targets    []CompositeChild `json:"targets" bson:"targets" validate:"min=1,dive"`
+}
+
+func (t *CompositeTarget) Type() TargetType {
+	return CompositeTargetType
+}
+
+func (t *CompositeTarget) Evaluate(ctx context.Context, acts []activities.Activity) (Progress, error) {
+	progress := CompositeTargetProgress{
+		Mode:     t.Mode,
+		Children: make([]CompositeChildProgress, 0, len(t.Targets)),
+	}
+
+	if len(t.Targets) == 0 {
+		return progress, nil
+	}
+
+	var weightSum, weightedSum float64
+	lowest, highest := math.Inf(1), math.Inf(-1)
+	for i, child := range t.Targets {
+		if child.Target.RealTarget == nil {
+			return nil, fmt.Errorf("%w: child target %d is not set", ErrInvalidTarget, i)
+		}
+
+		p, err := child.Target.RealTarget.Evaluate(ctx, acts)
+		if err != nil {
+			return nil, err
+		}
+
+		weight := child.Weight
+		if weight == 0 {
+			weight = 1
+		}
+
+		percent := p.Percentage()
+		lowest = math.Min(lowest, percent)
+		highest = math.Max(highest, percent)
+		weightSum += weight
+		weightedSum += weight * percent
+
+		progress.Children = append(progress.Children, CompositeChildProgress{
+			Type:     child.Target.RealTarget.Type(),
+			Weight:   weight,
+			Progress: p,
+		})
+	}
+
+	switch t.Mode {
+	case CompositeAny:
+		progress.Percent = highest
+	case CompositeWeighted:
+		progress.Percent = weightedSum / weightSum
+	default:
+		progress.Percent = lowest
+	}
+
+	return progress, nil
+}
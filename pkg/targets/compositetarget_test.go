@@ -0,0 +1,134 @@
+package targets_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/AustinBayley/activity_tracker_api/pkg/activities"
+	"github.com/AustinBayley/activity_tracker_api/pkg/targets"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func distanceChild(distance float64, weight float64, actType activities.ActivityType) targets.CompositeChild {
+	return targets.CompositeChild{
+		Target: targets.RawTarget{
+			RealTarget: &targets.DistanceTarget{
+				BaseTarget: targets.BaseTarget{
+					TargetType: targets.DistanceTargetType,
+				},
+				Distance:      distance,
+				ActivityTypes: []activities.ActivityType{actType},
+			},
+		},
+		Weight: weight,
+	}
+}
+
+func triathlon(mode targets.CompositeMode) *targets.CompositeTarget {
+	return &targets.CompositeTarget{
+		BaseTarget: targets.BaseTarget{
+			TargetType: targets.CompositeTargetType,
+		},
+		Mode: mode,
+		Targets: []targets.CompositeChild{
+			distanceChild(5, 1, activities.Swimming),
+			distanceChild(200, 2, activities.Cycling),
+			distanceChild(40, 1, activities.Running),
+		},
+	}
+}
+
+func TestCompositeTargetEvaluate(t *testing.T) {
+	acts := []activities.Activity{
+		activities.NewActivity(activities.Swimming, 5),
+		activities.NewActivity(activities.Cycling, 100),
+		activities.NewActivity(activities.Running, 10),
+	}
+
+	tests := []struct {
+		mode     targets.CompositeMode
+		expected float64
+	}{
+		{targets.CompositeAll, 25},
+		{targets.CompositeAny, 100},
+		{targets.CompositeWeighted, (100 + 2*50 + 25) / 4.0},
+	}
+
+	for _, tt := range tests {
+		progress, err := triathlon(tt.mode).Evaluate(context.Background(), acts)
+		if err != nil {
+			t.Fatalf("%s: expected no error, got %v", tt.mode, err)
+		}
+
+		p, ok := progress.(targets.CompositeTargetProgress)
+		if !ok {
+			t.Fatalf("%s: expected CompositeTargetProgress, got %T", tt.mode, progress)
+		}
+
+		if p.Percentage() != tt.expected {
+			t.Errorf("%s: expected percentage %f, got %f", tt.mode, tt.expected, p.Percentage())
+		}
+
+		if len(p.Children) != 3 {
+			t.Errorf("%s: expected 3 children, got %d", tt.mode, len(p.Children))
+		}
+	}
+}
+
+func TestCompositeTargetMarshal(t *testing.T) {
+	// Nest a composite target inside another to check it survives a round trip
+	target := triathlon(targets.CompositeAny)
+	target.Targets = append(target.Targets, targets.CompositeChild{
+		Target: targets.RawTarget{RealTarget: triathlon(targets.CompositeAll)},
+	})
+
+	jsonData, err := json.Marshal(target)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	var jsonRaw targets.RawTarget
+	if err := json.Unmarshal(jsonData, &jsonRaw); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	bsonData, err := bson.Marshal(target)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	var bsonRaw targets.RawTarget
+	if err := bson.Unmarshal(bsonData, &bsonRaw); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	for _, raw := range []targets.RawTarget{jsonRaw, bsonRaw} {
+		got, ok := raw.RealTarget.(*targets.CompositeTarget)
+		if !ok {
+			t.Fatalf("expected RealTarget to be of type CompositeTarget, got %T", raw.RealTarget)
+		}
+
+		if got.Mode != targets.CompositeAny || len(got.Targets) != 4 {
+			t.Fatalf("expected any mode with 4 children, got %s with %d", got.Mode, len(got.Targets))
+		}
+
+		child, ok := got.Targets[1].Target.RealTarget.(*targets.DistanceTarget)
+		if !ok {
+			t.Fatalf("expected child to be of type DistanceTarget, got %T", got.Targets[1].Target.RealTarget)
+		}
+
+		if child.Distance != 200 || got.Targets[1].Weight != 2 {
+			t.Errorf("expected child distance 200 and weight 2, got %f and %f", child.Distance, got.Targets[1].Weight)
+		}
+
+		nested, ok := got.Targets[3].Target.RealTarget.(*targets.CompositeTarget)
+		if !ok {
+			t.Fatalf("expected nested child to be of type CompositeTarget, got %T", got.Targets[3].Target.RealTarget)
+		}
+
+		if nested.Mode != targets.CompositeAll || len(nested.Targets) != 3 {
+			t.Errorf("expected nested all mode with 3 children, got %s with %d", nested.Mode, len(nested.Targets))
+		}
+	}
+}
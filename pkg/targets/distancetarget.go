@@ -0,0 +1,72 @@
+package targets
+
+import (
+	"context"
+	"math"
+
+	"github.com/AustinBayley/activity_tracker_api/pkg/activities"
+)
+
+var (
+	_ Target      = (*DistanceTarget)(nil)
+	_ Progress    = (*DistanceTargetProgress)(nil)
+	_ Projectable = (*DistanceTargetProgress)(nil)
+)
+
+const (
+	DistanceTargetType TargetType = "distanceTarget"
+)
+
+type DistanceTargetProgress struct {
+	Percent           float64 `json:"percent" bson:"percent"`
+	DistanceCovered   float64 `json:"distanceCovered" bson:"distanceCovered"`
+	DistanceRemaining float64 `json:"distanceRemaining" bson:"distanceRemaining"`
+}
+
+func (d DistanceTargetProgress) Percentage() float64 {
+	return d.Percent
+}
+
+func (d DistanceTargetProgress) Value() float64 {
+	return d.DistanceCovered
+}
+
+func (d DistanceTargetProgress) Remaining() float64 {
+	return d.DistanceRemaining
+}
+
+func (d DistanceTargetProgress) Unit() string {
+	return "km"
+}
+
+// DistanceTarget is a plain distance goal in km with no route attached.
+// If no activity types are given, any moving activity counts towards the goal.
+type DistanceTarget struct {
+	BaseTarget    `bson:",inline"`
+	Distance      float64                   `json:"distance" bson:"distance"`
+	ActivityTypes []activities.ActivityType `json:"activityTypes,omitempty" bson:"activityTypes,omitempty"`
+}
+
+func (t *DistanceTarget) Type() TargetType {
+	return DistanceTargetType
+}
+
+func (t *DistanceTarget) Evaluate(ctx context.Context, acts []activities.Activity) (Progress, error) {
+	var distance float64 = 0
+	for _, act := range acts {
+		if allowsActivity(t.ActivityTypes, act.Type) {
+			distance += act.Value
+		}
+	}
+
+	var percent float64 = 0
+	if distance > 0 && t.Distance > 0 {
+		percent = math.Min((distance/t.Distance)*100, 100)
+	}
+
+	return DistanceTargetProgress{
+		Percent:           percent,
+		DistanceCovered:   distance,
+		DistanceRemaining: math.Max(t.Distance-distance, 0),
+	}, nil
+}
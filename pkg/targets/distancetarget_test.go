@@ -0,0 +1,94 @@
+package targets_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/AustinBayley/activity_tracker_api/pkg/activities"
+	"github.com/AustinBayley/activity_tracker_api/pkg/targets"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func TestDistanceTargetEvaluate(t *testing.T) {
+	target := targets.DistanceTarget{
+		BaseTarget: targets.BaseTarget{
+			TargetType: targets.DistanceTargetType,
+		},
+		Distance:      100,
+		ActivityTypes: []activities.ActivityType{activities.Running},
+	}
+
+	acts := []activities.Activity{
+		activities.NewActivity(activities.Running, 30),
+		activities.NewActivity(activities.Running, 10),
+		activities.NewActivity(activities.Cycling, 50),
+	}
+
+	progress, err := target.Evaluate(context.Background(), acts)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	p, ok := progress.(targets.DistanceTargetProgress)
+	if !ok {
+		t.Fatalf("expected DistanceTargetProgress, got %T", progress)
+	}
+
+	if p.DistanceCovered != 40 {
+		t.Errorf("expected distance covered 40, got %f", p.DistanceCovered)
+	}
+
+	if p.DistanceRemaining != 60 {
+		t.Errorf("expected distance remaining 60, got %f", p.DistanceRemaining)
+	}
+
+	if p.Percentage() != 40 {
+		t.Errorf("expected percentage 40, got %f", p.Percentage())
+	}
+}
+
+func TestDistanceTargetMarshal(t *testing.T) {
+	target := targets.DistanceTarget{
+		BaseTarget: targets.BaseTarget{
+			TargetType: targets.DistanceTargetType,
+		},
+		Distance:      100,
+		ActivityTypes: []activities.ActivityType{activities.Running, activities.Walking},
+	}
+
+	jsonData, err := json.Marshal(&target)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	var jsonRaw targets.RawTarget
+	if err := json.Unmarshal(jsonData, &jsonRaw); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	bsonData, err := bson.Marshal(&target)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	var bsonRaw targets.RawTarget
+	if err := bson.Unmarshal(bsonData, &bsonRaw); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	for _, raw := range []targets.RawTarget{jsonRaw, bsonRaw} {
+		got, ok := raw.RealTarget.(*targets.DistanceTarget)
+		if !ok {
+			t.Fatalf("expected RealTarget to be of type DistanceTarget, got %T", raw.RealTarget)
+		}
+
+		if got.Distance != target.Distance {
+			t.Errorf("expected distance %f, got %f", target.Distance, got.Distance)
+		}
+
+		if len(got.ActivityTypes) != 2 {
+			t.Errorf("expected 2 activity types, got %d", len(got.ActivityTypes))
+		}
+	}
+}
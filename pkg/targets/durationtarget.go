@@ -0,0 +1,81 @@
+package targets
+
+import (
+	"context"
+	"math"
+
+	"github.com/AustinBayley/activity_tracker_api/pkg/activities"
+)
+
+var (
+	_ Target      = (*DurationTarget)(nil)
+	_ Progress    = (*DurationTargetProgress)(nil)
+	_ Projectable = (*DurationTargetProgress)(nil)
+)
+
+const (
+	DurationTargetType TargetType = "durationTarget"
+)
+
+// DurationTargetProgress reports time in minutes.
+type DurationTargetProgress struct {
+	Percent       float64 `json:"percent" bson:"percent"`
+	TimeSpent     float64 `json:"timeSpent" bson:"timeSpent"`
+	TimeRemaining float64 `json:"timeRemaining" bson:"timeRemaining"`
+}
+
+func (d DurationTargetProgress) Percentage() float64 {
+	return d.Percent
+}
+
+func (d DurationTargetProgress) Value() float64 {
+	return d.TimeSpent
+}
+
+func (d DurationTargetProgress) Remaining() float64 {
+	return d.TimeRemaining
+}
+
+func (d DurationTargetProgress) Unit() string {
+	return "minutes"
+}
+
+// DurationTarget is a goal of time spent on activities, in minutes.
+// Activity values are ignored; only the time between Start and End is counted.
+type DurationTarget struct {
+	BaseTarget    `bson:",inline"`
+	Duration      float64                   `json:"duration" bson:"duration"`
+	ActivityTypes []activities.ActivityType `json:"activityTypes,omitempty" bson:"activityTypes,omitempty"`
+}
+
+func (t *DurationTarget) Type() TargetType {
+	return DurationTargetType
+}
+
+func (t *DurationTarget) Evaluate(ctx context.Context, acts []activities.Activity) (Progress, error) {
+	var spent float64 = 0
+	for _, act := range acts {
+		if allowsActivity(t.ActivityTypes, act.Type) {
+			spent += activityMinutes(act)
+		}
+	}
+
+	var percent float64 = 0
+	if spent > 0 && t.Duration > 0 {
+		percent = math.Min((spent/t.Duration)*100, 100)
+	}
+
+	return DurationTargetProgress{
+		Percent:       percent,
+		TimeSpent:     spent,
+		TimeRemaining: math.Max(t.Duration-spent, 0),
+	}, nil
+}
+
+// activityMinutes returns the time spent on an activity in minutes.
+func activityMinutes(act activities.Activity) float64 {
+	if act.End.Before(act.Start) {
+		return 0
+	}
+	return act.End.Sub(act.Start).Minutes()
+}
@@ -0,0 +1,68 @@
+package targets_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/AustinBayley/activity_tracker_api/pkg/activities"
+	"github.com/AustinBayley/activity_tracker_api/pkg/targets"
+)
+
+func TestDurationTargetEvaluate(t *testing.T) {
+	target := targets.DurationTarget{
+		BaseTarget: targets.BaseTarget{
+			TargetType: targets.DurationTargetType,
+		},
+		Duration:      20 * 60,
+		ActivityTypes: []activities.ActivityType{activities.Cycling},
+	}
+
+	start := time.Date(2025, time.October, 1, 9, 0, 0, 0, time.UTC)
+	acts := []activities.Activity{
+		{Type: activities.Cycling, Value: 1, Start: start, End: start.Add(3 * time.Hour)},
+		{Type: activities.Cycling, Value: 100, Start: start, End: start.Add(2 * time.Hour)},
+		{Type: activities.Running, Value: 10, Start: start, End: start.Add(5 * time.Hour)},
+	}
+
+	progress, err := target.Evaluate(context.Background(), acts)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	p, ok := progress.(targets.DurationTargetProgress)
+	if !ok {
+		t.Fatalf("expected DurationTargetProgress, got %T", progress)
+	}
+
+	if p.TimeSpent != 300 {
+		t.Errorf("expected time spent 300, got %f", p.TimeSpent)
+	}
+
+	if p.TimeRemaining != 900 {
+		t.Errorf("expected time remaining 900, got %f", p.TimeRemaining)
+	}
+
+	if p.Percentage() != 25 {
+		t.Errorf("expected percentage 25, got %f", p.Percentage())
+	}
+}
+
+func TestDurationTargetUnmarshal(t *testing.T) {
+	data := []byte(`{"type":"durationTarget","duration":1200,"activityTypes":["cycling"]}`)
+
+	var raw targets.RawTarget
+	if err := json.Unmarshal(data, &raw); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	got, ok := raw.RealTarget.(*targets.DurationTarget)
+	if !ok {
+		t.Fatalf("expected RealTarget to be of type DurationTarget, got %T", raw.RealTarget)
+	}
+
+	if got.Duration != 1200 {
+		t.Errorf("expected duration 1200, got %f", got.Duration)
+	}
+}
@@ -0,0 +1,125 @@
+package targets
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/AustinBayley/activity_tracker_api/pkg/activities"
+)
+
+var (
+	_ Target   = (*FrequencyTarget)(nil)
+	_ Progress = (*FrequencyTargetProgress)(nil)
+)
+
+const (
+	FrequencyTargetType TargetType = "frequencyTarget"
+)
+
+// WeekResult is the number of qualifying sessions logged in the ISO week beginning at Start.
+type WeekResult struct {
+	Start    time.Time `json:"start" bson:"start"`
+	Sessions int       `json:"sessions" bson:"sessions"`
+	Passed   bool      `json:"passed" bson:"passed"`
+}
+
+type FrequencyTargetProgress struct {
+	Percent       float64      `json:"percent" bson:"percent"`
+	WeeksPassed   int          `json:"weeksPassed" bson:"weeksPassed"`
+	WeeksRequired int          `json:"weeksRequired" bson:"weeksRequired"`
+	Weeks         []WeekResult `json:"weeks" bson:"weeks"`
+}
+
+func (f FrequencyTargetProgress) Percentage() float64 {
+	return f.Percent
+}
+
+// FrequencyTarget is a goal of a number of sessions per week for a number of weeks.
+// A session is a single matching activity of at least MinDistance km or MinDuration minutes;
+// if neither threshold is set, any matching activity is a session.
+// Only activities within the evaluation window (see WithWindow) are counted.
+type FrequencyTarget struct {
+	BaseTarget    `bson:",inline"`
+	Sessions      int                       `json:"sessions" bson:"sessions" validate:"gt=0"`
+	Weeks         int                       `json:"weeks" bson:"weeks" validate:"gt=0"`
+	Timezone      string                    `json:"timezone,omitempty" bson:"timezone,omitempty" validate:"omitempty,timezone"`
+	MinDistance   float64                   `json:"minDistance,omitempty" bson:"minDistance,omitempty"`
+	MinDuration   float64                   `json:"minDuration,omitempty" bson:"minDuration,omitempty"`
+	ActivityTypes []activities.ActivityType `json:"activityTypes,omitempty" bson:"activityTypes,omitempty"`
+}
+
+func (t *FrequencyTarget) Type() TargetType {
+	return FrequencyTargetType
+}
+
+func (t *FrequencyTarget) isSession(act activities.Activity) bool {
+	if !allowsActivity(t.ActivityTypes, act.Type) {
+		return false
+	}
+	if t.MinDistance <= 0 && t.MinDuration <= 0 {
+		return true
+	}
+	return (t.MinDistance > 0 && act.Value >= t.MinDistance) ||
+		(t.MinDuration > 0 && activityMinutes(act) >= t.MinDuration)
+}
+
+func (t *FrequencyTarget) Evaluate(ctx context.Context, acts []activities.Activity) (Progress, error) {
+	loc, err := loadLocation(t.Timezone)
+	if err != nil {
+		return nil, err
+	}
+
+	window, _ := WindowFromContext(ctx)
+
+	sessions := map[int64]int{}
+	var first time.Time
+	for _, act := range acts {
+		if !window.Contains(act.Start) || !t.isSession(act) {
+			continue
+		}
+
+		if first.IsZero() || act.Start.Before(first) {
+			first = act.Start
+		}
+		sessions[Week.Start(act.Start, loc).Unix()]++
+	}
+
+	// Weeks are listed from the start of the window (or the first session if there isn't one)
+	// up to the end of the window or the current week, whichever is sooner.
+	from := window.Start
+	if from.IsZero() {
+		from = first
+	}
+
+	to := AsOf(ctx)
+	if !window.End.IsZero() && window.End.Before(to) {
+		to = window.End
+	}
+
+	progress := FrequencyTargetProgress{
+		WeeksRequired: t.Weeks,
+		Weeks:         make([]WeekResult, 0),
+	}
+
+	if !from.IsZero() {
+		last := Week.Start(to, loc)
+		for start := Week.Start(from, loc); !start.After(last); start = Week.Next(start) {
+			week := WeekResult{
+				Start:    start,
+				Sessions: sessions[start.Unix()],
+				Passed:   sessions[start.Unix()] >= t.Sessions,
+			}
+			if week.Passed {
+				progress.WeeksPassed++
+			}
+			progress.Weeks = append(progress.Weeks, week)
+		}
+	}
+
+	if progress.WeeksPassed > 0 && t.Weeks > 0 {
+		progress.Percent = math.Min((float64(progress.WeeksPassed)/float64(t.Weeks))*100, 100)
+	}
+
+	return progress, nil
+}
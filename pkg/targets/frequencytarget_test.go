@@ -0,0 +1,88 @@
+package targets_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/AustinBayley/activity_tracker_api/pkg/activities"
+	"github.com/AustinBayley/activity_tracker_api/pkg/targets"
+)
+
+func TestFrequencyTargetEvaluate(t *testing.T) {
+	target := targets.FrequencyTarget{
+		BaseTarget: targets.BaseTarget{
+			TargetType: targets.FrequencyTargetType,
+		},
+		Sessions:    2,
+		Weeks:       4,
+		MinDuration: 20,
+	}
+
+	// 2025-09-01 is a Monday
+	start := time.Date(2025, time.September, 1, 0, 0, 0, 0, time.UTC)
+	window := targets.Window{
+		Start: start,
+		End:   start.AddDate(0, 0, 21).Add(-time.Second),
+	}
+	session := func(day int, minutes int) activities.Activity {
+		s := start.AddDate(0, 0, day).Add(8 * time.Hour)
+		return activities.Activity{Type: activities.Running, Value: 5, Start: s, End: s.Add(time.Duration(minutes) * time.Minute)}
+	}
+
+	acts := []activities.Activity{
+		// Before the window
+		session(-3, 30),
+		session(-2, 30),
+		// Week 1 passes
+		session(0, 30),
+		session(2, 45),
+		// Week 2 fails, one session is too short
+		session(8, 30),
+		session(9, 10),
+		// Week 3 passes
+		session(14, 20),
+		session(15, 20),
+		session(16, 20),
+		// After the window
+		session(21, 30),
+		session(22, 30),
+	}
+
+	progress, err := target.Evaluate(targets.WithWindow(context.Background(), window), acts)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	p, ok := progress.(targets.FrequencyTargetProgress)
+	if !ok {
+		t.Fatalf("expected FrequencyTargetProgress, got %T", progress)
+	}
+
+	if len(p.Weeks) != 3 {
+		t.Fatalf("expected 3 weeks, got %d", len(p.Weeks))
+	}
+
+	expected := []struct {
+		sessions int
+		passed   bool
+	}{
+		{2, true},
+		{1, false},
+		{3, true},
+	}
+	for i, week := range p.Weeks {
+		if week.Sessions != expected[i].sessions || week.Passed != expected[i].passed {
+			t.Errorf("week %d: expected %d sessions (passed %t), got %d (passed %t)",
+				i, expected[i].sessions, expected[i].passed, week.Sessions, week.Passed)
+		}
+	}
+
+	if p.WeeksPassed != 2 {
+		t.Errorf("expected 2 weeks passed, got %d", p.WeeksPassed)
+	}
+
+	if p.Percentage() != 50 {
+		t.Errorf("expected percentage 50, got %f", p.Percentage())
+	}
+}
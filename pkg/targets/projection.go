@@ -0,0 +1,122 @@
+package targets
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/AustinBayley/activity_tracker_api/pkg/activities"
+)
+
+const (
+	// PercentUnit is used to project progress which isn't Projectable.
+	PercentUnit = "percent"
+	// MaxProjectionDays is how far ahead a completion date is projected. Rates too slow to
+	// complete the target within it aren't given a completion date.
+	MaxProjectionDays = 10 * 365
+)
+
+// Projectable is implemented by progress that can be projected in the target's own units,
+// e.g. km for a distance target. Other progress is projected in percent.
+type Projectable interface {
+	Progress
+	// Value returns the amount achieved so far.
+	Value() float64
+	// Remaining returns the amount left to complete the target.
+	Remaining() float64
+	// Unit returns the unit value and remaining are measured in.
+	Unit() string
+}
+
+// Projection predicts when a target will be completed, based on the average daily rate
+// of progress over a recent number of days.
+type Projection struct {
+	Unit string `json:"unit" bson:"unit"`
+	// Days is the number of days the rate was averaged over.
+	Days int `json:"days" bson:"days"`
+	// Rate is the average progress made per day.
+	Rate      float64 `json:"rate" bson:"rate"`
+	Remaining float64 `json:"remaining" bson:"remaining"`
+	// CompletionDate is when the target will be completed at the current rate. It is not set
+	// if no recent progress has been made, or if completing would take over MaxProjectionDays.
+	CompletionDate *time.Time `json:"completionDate,omitempty" bson:"completionDate,omitempty"`
+	// OnTrack reports whether the target will be completed by the end of the window.
+	OnTrack bool `json:"onTrack" bson:"onTrack"`
+	// RequiredRate is the progress needed per day to complete the target by the end of the
+	// window. It is not set once the window has ended.
+	RequiredRate *float64 `json:"requiredRate,omitempty" bson:"requiredRate,omitempty"`
+}
+
+// measure returns the progress made and remaining, along with their unit.
+func measure(p Progress) (float64, float64, string) {
+	if pp, ok := p.(Projectable); ok {
+		return pp.Value(), pp.Remaining(), pp.Unit()
+	}
+	return p.Percentage(), math.Max(100-p.Percentage(), 0), PercentUnit
+}
+
+// Project evaluates the activities against the target at now and the given number of days
+// earlier, and projects the rate of progress between them forward to the end of the window.
+func Project(ctx context.Context, t Target, acts []activities.Activity, window Window, days int, now time.Time) (Projection, error) {
+	ctx = WithWindow(ctx, window)
+
+	sorted := Chronological(acts)
+	before := func(at time.Time) []activities.Activity {
+		n := 0
+		for n < len(sorted) && !sorted[n].End.After(at) {
+			n++
+		}
+		return sorted[:n]
+	}
+
+	// Progress can't be made before the window starts, so don't average over that time
+	elapsed := float64(days)
+	if !window.Start.IsZero() {
+		elapsed = math.Min(elapsed, now.Sub(window.Start).Hours()/24)
+	}
+	since := now.Add(-time.Duration(elapsed * float64(24*time.Hour)))
+
+	current, err := t.Evaluate(ctx, before(now))
+	if err != nil {
+		return Projection{}, err
+	}
+
+	past, err := t.Evaluate(ctx, before(since))
+	if err != nil {
+		return Projection{}, err
+	}
+
+	value, remaining, unit := measure(current)
+	pastValue, _, _ := measure(past)
+
+	projection := Projection{
+		Unit:      unit,
+		Days:      days,
+		Remaining: remaining,
+	}
+
+	if elapsed > 0 {
+		projection.Rate = math.Max(value-pastValue, 0) / elapsed
+	}
+
+	daysLeft := 0.0
+	if !window.End.IsZero() {
+		daysLeft = window.End.Sub(now).Hours() / 24
+	}
+
+	switch {
+	case remaining <= 0:
+		projection.OnTrack = true
+	case projection.Rate > 0 && remaining/projection.Rate <= MaxProjectionDays:
+		eta := now.Add(time.Duration(remaining / projection.Rate * float64(24*time.Hour)))
+		projection.CompletionDate = &eta
+		projection.OnTrack = window.End.IsZero() || !eta.After(window.End)
+	}
+
+	if daysLeft > 0 {
+		required := math.Max(remaining, 0) / daysLeft
+		projection.RequiredRate = &required
+	}
+
+	return projection, nil
+}
@@ -0,0 +1,126 @@
+package targets_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/AustinBayley/activity_tracker_api/pkg/activities"
+	"github.com/AustinBayley/activity_tracker_api/pkg/targets"
+)
+
+func TestProject(t *testing.T) {
+	target := &targets.DistanceTarget{
+		BaseTarget: targets.BaseTarget{
+			TargetType: targets.DistanceTargetType,
+		},
+		Distance: 100,
+	}
+
+	now := time.Date(2025, time.October, 15, 12, 0, 0, 0, time.UTC)
+	run := func(daysAgo int, value float64) activities.Activity {
+		s := now.AddDate(0, 0, -daysAgo)
+		return activities.Activity{Type: activities.Running, Value: value, Start: s.Add(-time.Hour), End: s}
+	}
+
+	// 20 km before the last 10 days, then 20 km during them: 2 km a day
+	acts := []activities.Activity{
+		run(20, 20),
+		run(8, 10),
+		run(2, 10),
+	}
+
+	window := targets.Window{
+		Start: now.AddDate(0, 0, -30),
+		End:   now.AddDate(0, 0, 20),
+	}
+
+	projection, err := targets.Project(context.Background(), target, acts, window, 10, now)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if projection.Unit != "km" {
+		t.Errorf("expected unit km, got %s", projection.Unit)
+	}
+
+	if projection.Rate != 2 {
+		t.Errorf("expected rate 2, got %f", projection.Rate)
+	}
+
+	if projection.Remaining != 60 {
+		t.Errorf("expected remaining 60, got %f", projection.Remaining)
+	}
+
+	// 60 km at 2 km a day takes 30 days, beyond the 20 left
+	if expected := now.AddDate(0, 0, 30); projection.CompletionDate == nil || !projection.CompletionDate.Equal(expected) {
+		t.Errorf("expected completion date %s, got %v", expected, projection.CompletionDate)
+	}
+
+	if projection.OnTrack {
+		t.Error("expected not to be on track")
+	}
+
+	if projection.RequiredRate == nil || *projection.RequiredRate != 3 {
+		t.Errorf("expected required rate 3, got %v", projection.RequiredRate)
+	}
+}
+
+func TestProjectTooSlow(t *testing.T) {
+	target := &targets.DistanceTarget{
+		BaseTarget: targets.BaseTarget{
+			TargetType: targets.DistanceTargetType,
+		},
+		Distance: 100,
+	}
+
+	// 10 m in 14 days would take centuries to cover 100 km
+	now := time.Date(2025, time.October, 15, 12, 0, 0, 0, time.UTC)
+	acts := []activities.Activity{
+		{Type: activities.Running, Value: 0.01, Start: now.AddDate(0, 0, -1).Add(-time.Hour), End: now.AddDate(0, 0, -1)},
+	}
+
+	window := targets.Window{
+		Start: now.AddDate(0, 0, -14),
+		End:   now.AddDate(0, 0, 14),
+	}
+
+	projection, err := targets.Project(context.Background(), target, acts, window, 14, now)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if projection.Rate <= 0 {
+		t.Fatalf("expected a rate, got %f", projection.Rate)
+	}
+
+	if projection.CompletionDate != nil {
+		t.Errorf("expected no completion date, got %s", projection.CompletionDate)
+	}
+
+	if projection.OnTrack {
+		t.Error("expected not to be on track")
+	}
+}
+
+func TestProjectPercent(t *testing.T) {
+	target := &targets.StreakTarget{
+		BaseTarget: targets.BaseTarget{
+			TargetType: targets.StreakTargetType,
+		},
+		Length: 10,
+	}
+
+	projection, err := targets.Project(context.Background(), target, nil, targets.Window{}, 14, time.Now())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if projection.Unit != targets.PercentUnit {
+		t.Errorf("expected unit %s, got %s", targets.PercentUnit, projection.Unit)
+	}
+
+	if projection.Remaining != 100 || projection.CompletionDate != nil {
+		t.Errorf("expected 100 remaining and no completion date, got %f and %v", projection.Remaining, projection.CompletionDate)
+	}
+}
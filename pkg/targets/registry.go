@@ -0,0 +1,62 @@
+package targets
+
+import (
+	"sort"
+	"sync"
+)
+
+// Factory returns a new, empty instance of a target to unmarshal into.
+type Factory func() Target
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[TargetType]Factory{}
+)
+
+func init() {
+	Register(RouteMovingTargetType, func() Target { return &RouteMovingTarget{} })
+	Register(DistanceTargetType, func() Target { return &DistanceTarget{} })
+	Register(DurationTargetType, func() Target { return &DurationTarget{} })
+	Register(StreakTargetType, func() Target { return &StreakTarget{} })
+	Register(FrequencyTargetType, func() Target { return &FrequencyTarget{} })
+	Register(CompositeTargetType, func() Target { return &CompositeTarget{} })
+}
+
+// Register makes a target type available to RawTarget when unmarshalling.
+// Registering a type that already exists replaces its factory.
+func Register(targetType TargetType, factory Factory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	registry[targetType] = factory
+}
+
+// Types returns the registered target types in alphabetical order.
+func Types() []TargetType {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	types := make([]TargetType, 0, len(registry))
+	for t := range registry {
+		types = append(types, t)
+	}
+	sort.Slice(types, func(i, j int) bool {
+		return types[i] < types[j]
+	})
+
+	return types
+}
+
+// resolveType returns a new instance of the target type based on the provided TargetType,
+// or nil if the type has not been registered.
+func resolveType(targetType TargetType) Target {
+	registryMu.RLock()
+	factory, ok := registry[targetType]
+	registryMu.RUnlock()
+
+	if !ok {
+		return nil
+	}
+
+	return factory()
+}
@@ -0,0 +1,111 @@
+package targets_test
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"testing"
+
+	"github.com/AustinBayley/activity_tracker_api/pkg/activities"
+	"github.com/AustinBayley/activity_tracker_api/pkg/targets"
+)
+
+const countingTargetType targets.TargetType = "countingTarget"
+
+type countingProgress struct {
+	Count int `json:"count"`
+}
+
+func (c countingProgress) Percentage() float64 {
+	return float64(c.Count)
+}
+
+type countingTarget struct {
+	targets.BaseTarget `bson:",inline"`
+	Label              string `json:"label" bson:"label" validate:"required"`
+}
+
+func (t *countingTarget) Type() targets.TargetType {
+	return countingTargetType
+}
+
+func (t *countingTarget) Evaluate(ctx context.Context, acts []activities.Activity) (targets.Progress, error) {
+	return countingProgress{Count: len(acts)}, nil
+}
+
+func TestRegister(t *testing.T) {
+	targets.Register(countingTargetType, func() targets.Target { return &countingTarget{} })
+
+	if !slices.Contains(targets.Types(), countingTargetType) {
+		t.Fatalf("expected %s to be registered", countingTargetType)
+	}
+
+	var raw targets.RawTarget
+	if err := json.Unmarshal([]byte(`{"type":"countingTarget","label":"sessions"}`), &raw); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	got, ok := raw.RealTarget.(*countingTarget)
+	if !ok {
+		t.Fatalf("expected RealTarget to be of type countingTarget, got %T", raw.RealTarget)
+	}
+
+	if got.Label != "sessions" {
+		t.Errorf("expected label sessions, got %s", got.Label)
+	}
+
+	schema, ok := targets.Schema(countingTargetType)
+	if !ok {
+		t.Fatal("expected schema for registered type")
+	}
+
+	if required, _ := schema["required"].([]string); !slices.Contains(required, "label") {
+		t.Errorf("expected label to be required, got %v", schema["required"])
+	}
+}
+
+func TestUnknownType(t *testing.T) {
+	var raw targets.RawTarget
+	if err := json.Unmarshal([]byte(`{"type":"unknownTarget"}`), &raw); err != targets.ErrInvalidTarget {
+		t.Errorf("expected ErrInvalidTarget, got %v", err)
+	}
+
+	if _, ok := targets.Schema("unknownTarget"); ok {
+		t.Error("expected no schema for unknown type")
+	}
+}
+
+func TestSchema(t *testing.T) {
+	schema, ok := targets.Schema(targets.StreakTargetType)
+	if !ok {
+		t.Fatal("expected schema for streak target")
+	}
+
+	props, ok := schema["properties"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected properties, got %T", schema["properties"])
+	}
+
+	typ, _ := props["type"].(map[string]interface{})
+	if typ["const"] != string(targets.StreakTargetType) {
+		t.Errorf("expected type to be constant %s, got %v", targets.StreakTargetType, typ["const"])
+	}
+
+	period, _ := props["period"].(map[string]interface{})
+	if enum, _ := period["enum"].([]string); !slices.Equal(enum, []string{"day", "week"}) {
+		t.Errorf("expected period enum [day week], got %v", period["enum"])
+	}
+
+	required, _ := schema["required"].([]string)
+	if !slices.Contains(required, "length") || slices.Contains(required, "timezone") {
+		t.Errorf("expected length and not timezone to be required, got %v", required)
+	}
+
+	// Every built in schema should be serialisable
+	for _, tt := range targets.Types() {
+		s, _ := targets.Schema(tt)
+		if _, err := json.Marshal(s); err != nil {
+			t.Errorf("%s: expected no error marshalling schema, got %v", tt, err)
+		}
+	}
+}
@@ -3,16 +3,20 @@ package targets
 import (
 	"context"
 	"errors"
+	"fmt"
 	"math"
+	"time"
 
 	"github.com/AustinBayley/activity_tracker_api/pkg/activities"
 	"github.com/AustinBayley/activity_tracker_api/pkg/locations"
+	"github.com/AustinBayley/activity_tracker_api/pkg/service"
 	"go.mongodb.org/mongo-driver/v2/bson"
 )
 
 var (
-	_ Target   = (*RouteMovingTarget)(nil)
-	_ Progress = (*RouteMovingTargetProgress)(nil)
+	_ Target      = (*RouteMovingTarget)(nil)
+	_ Progress    = (*RouteMovingTargetProgress)(nil)
+	_ Projectable = (*RouteMovingTargetProgress)(nil)
 )
 
 const (
@@ -21,11 +25,99 @@ const (
 
 var (
 	ErrFindingLocation = errors.New("error finding location")
+	ErrResolvingRoute  = errors.New("error resolving route")
 )
 
+// RouteResolver looks up routes shared across challenges by their ID.
+type RouteResolver interface {
+	ResolveRoute(ctx context.Context, id service.ID) (*Route, error)
+}
+
+type routeResolverCtxKey struct{}
+
+// WithRouteResolver returns a copy of ctx carrying the resolver used to look up referenced routes.
+func WithRouteResolver(ctx context.Context, r RouteResolver) context.Context {
+	return context.WithValue(ctx, routeResolverCtxKey{}, r)
+}
+
+// RouteResolverFromContext returns the route resolver stored in ctx, if any.
+func RouteResolverFromContext(ctx context.Context) (RouteResolver, bool) {
+	r, ok := ctx.Value(routeResolverCtxKey{}).(RouteResolver)
+	return r, ok && r != nil
+}
+
 // Can't use Route as Google don't allow caching / storage of data from the Directions API
 type Route struct {
 	locations.Waypoints `json:"waypoints" bson:"waypoints"`
+	// Tolerance is how far in metres the route may be simplified when saved, defaulting to
+	// locations.DefaultTolerance.
+	Tolerance float64 `json:"tolerance,omitempty" bson:"tolerance,omitempty" validate:"gte=0"`
+	// Simplification reports how the route was changed by being simplified, if it was.
+	Simplification *locations.Simplification `json:"simplification,omitempty" bson:"simplification,omitempty"`
+	// Distances indexes the waypoints, and is calculated when the route is saved.
+	Distances locations.Index `json:"-" bson:"distances,omitempty"`
+	// Cells are the H3 cells the waypoints are in, so the route can be found by location. They
+	// are calculated when the route is saved.
+	Cells []string `json:"-" bson:"cells,omitempty"`
+}
+
+// Index returns the cumulative distance to each waypoint, only calculating it if the stored
+// index is missing or out of date.
+func (r *Route) Index() locations.Index {
+	if len(r.Distances) != len(r.Waypoints) {
+		r.Distances = locations.NewIndex(r.Waypoints)
+	}
+	return r.Distances
+}
+
+// Distance returns the total distance of the route in km.
+func (r *Route) Distance() float64 {
+	return r.Index().Total()
+}
+
+// GetLocation returns the location the given distance along the route.
+func (r *Route) GetLocation(distance float64) (locations.Location, error) {
+	return r.Waypoints.Locate(r.Index(), distance)
+}
+
+// Checkpoints returns the checkpoints along the route.
+func (r *Route) Checkpoints() []locations.Checkpoint {
+	return r.Waypoints.Checkpoints(r.Index())
+}
+
+// Simplify simplifies the route's waypoints to its tolerance and at most locations.MaxWaypoints.
+// The original distance and number of waypoints are kept across repeated simplifications. It
+// returns locations.ErrTooManyWaypoints if the route has too many waypoints that must be kept.
+func (r *Route) Simplify() error {
+	// The waypoints have been replaced since they were last simplified
+	if r.Simplification != nil && r.Simplification.Waypoints != len(r.Waypoints) {
+		r.Simplification = nil
+	}
+
+	tolerance := r.Tolerance
+	if tolerance == 0 {
+		tolerance = locations.DefaultTolerance
+	}
+
+	simplified, s, err := r.Waypoints.Simplify(tolerance, locations.MaxWaypoints)
+	if err != nil {
+		return err
+	}
+
+	if s.Waypoints == s.OriginalWaypoints {
+		return nil
+	}
+
+	if r.Simplification != nil {
+		s.OriginalWaypoints = r.Simplification.OriginalWaypoints
+		s.OriginalDistance = r.Simplification.OriginalDistance
+		s.Tolerance = math.Max(s.Tolerance, r.Simplification.Tolerance)
+	}
+
+	r.Waypoints = simplified
+	r.Simplification = &s
+	r.Distances = locations.NewIndex(simplified)
+	return nil
 }
 
 func (r *Route) MarshalBSON() ([]byte, error) {
@@ -34,42 +126,140 @@ func (r *Route) MarshalBSON() ([]byte, error) {
 		r.Waypoints = make(locations.Waypoints, 0)
 	}
 
+	cells, err := r.Waypoints.Cells()
+	if err != nil {
+		return nil, err
+	}
+	r.Cells = cells
+
 	return bson.Marshal((*RawRoute)(r))
 }
 
+// TypeDistance is the distance covered by one activity type, before and after its multiplier.
+type TypeDistance struct {
+	Raw      float64 `json:"raw" bson:"raw"`
+	Weighted float64 `json:"weighted" bson:"weighted"`
+}
+
+// ReachedCheckpoint is a checkpoint the user has passed and when they passed it.
+type ReachedCheckpoint struct {
+	locations.Checkpoint `json:",inline" bson:",inline"`
+	ReachedAt            time.Time `json:"reachedAt" bson:"reachedAt"`
+}
+
+// NextCheckpoint is the next checkpoint along the route and how far the user is from it.
+type NextCheckpoint struct {
+	locations.Checkpoint `json:",inline" bson:",inline"`
+	DistanceRemaining    float64 `json:"distanceRemaining" bson:"distanceRemaining"`
+}
+
 type RouteMovingTargetProgress struct {
-	Percent         float64            `json:"percent" bson:"percent"`
-	DistanceCovered float64            `json:"distanceCovered" bson:"distanceCovered"`
-	Location        locations.Location `json:"location" bson:"location"`
+	Percent           float64                                  `json:"percent" bson:"percent"`
+	DistanceCovered   float64                                  `json:"distanceCovered" bson:"distanceCovered"`
+	DistanceRemaining float64                                  `json:"distanceRemaining" bson:"distanceRemaining"`
+	Location          locations.Location                       `json:"location" bson:"location"`
+	Distances         map[activities.ActivityType]TypeDistance `json:"distances" bson:"distances"`
+	Checkpoints       []ReachedCheckpoint                      `json:"checkpoints" bson:"checkpoints"`
+	NextCheckpoint    *NextCheckpoint                          `json:"nextCheckpoint,omitempty" bson:"nextCheckpoint,omitempty"`
 }
 
 func (r RouteMovingTargetProgress) Percentage() float64 {
 	return r.Percent
 }
 
+func (r RouteMovingTargetProgress) Value() float64 {
+	return r.DistanceCovered
+}
+
+func (r RouteMovingTargetProgress) Remaining() float64 {
+	return r.DistanceRemaining
+}
+
+func (r RouteMovingTargetProgress) Unit() string {
+	return "km"
+}
+
+// RouteMovingTarget moves the user along a route by the distance of their activities.
+// Activities are limited to ActivityTypes (any moving activity if empty) and each type's
+// distance is scaled by its entry in Multipliers, which defaults to 1.
+// The route is either inlined, or referenced from the route library by RouteID.
 type RouteMovingTarget struct {
 	BaseTarget    `bson:",inline"`
-	Route         Route   `json:"route" bson:"route"`
-	TotalDistance float64 `json:"totalDistance" bson:"totalDistance"`
+	RouteID       *service.ID                         `json:"routeId,omitempty" bson:"routeId,omitempty"`
+	Route         Route                               `json:"route" bson:"route"`
+	TotalDistance float64                             `json:"totalDistance" bson:"totalDistance"`
+	ActivityTypes []activities.ActivityType           `json:"activityTypes,omitempty" bson:"activityTypes,omitempty"`
+	Multipliers   map[activities.ActivityType]float64 `json:"multipliers,omitempty" bson:"multipliers,omitempty" validate:"omitempty,dive,gte=0"`
+
+	// resolved is set once a referenced route has been looked up.
+	resolved bool
+}
+
+// Resolve replaces a referenced route with the route from the library, using the RouteResolver
+// in ctx. It does nothing if the route is inlined or has already been resolved.
+func (t *RouteMovingTarget) Resolve(ctx context.Context) error {
+	if t.RouteID == nil || t.resolved {
+		return nil
+	}
+
+	resolver, ok := RouteResolverFromContext(ctx)
+	if !ok {
+		return fmt.Errorf("%w: no resolver for route %s", ErrResolvingRoute, t.RouteID.ConvertID())
+	}
+
+	route, err := resolver.ResolveRoute(ctx, *t.RouteID)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrResolvingRoute, err)
+	}
+
+	t.Route = *route
+	t.TotalDistance = t.Route.Distance()
+	t.resolved = true
+
+	return nil
+}
+
+// Multiplier returns the weighting applied to distance covered by the given activity type.
+func (t *RouteMovingTarget) Multiplier(actType activities.ActivityType) float64 {
+	if m, ok := t.Multipliers[actType]; ok {
+		return m
+	}
+	return 1
 }
 
 func (t *RouteMovingTarget) MarshalBSON() ([]byte, error) {
 	type RawRouteMovingTarget RouteMovingTarget
 
+	// Referenced routes are stored in the route library rather than with the target
+	if t.RouteID != nil {
+		raw := RawRouteMovingTarget(*t)
+		raw.Route = Route{}
+		return bson.Marshal(&raw)
+	}
+
 	if len(t.Route.Waypoints) < 2 {
 		return bson.Marshal((*RawRouteMovingTarget)(t))
 	}
 
-	previous := t.Route.Waypoints[0]
+	t.Route.Distances = locations.NewIndex(t.Route.Waypoints)
+	t.TotalDistance = t.Route.Distances.Total()
 
-	var distanceSum float64 = 0
-	for _, waypoint := range t.Route.Waypoints[1:] {
-		distanceSum += previous.DistanceTo(waypoint)
-		previous = waypoint
+	return bson.Marshal((*RawRouteMovingTarget)(t))
+}
+
+// Simplify simplifies an inlined route before it is saved and updates the total distance to
+// match. Referenced routes are simplified when they are saved to the route library.
+func (t *RouteMovingTarget) Simplify() error {
+	if t.RouteID != nil || len(t.Route.Waypoints) < 2 {
+		return nil
 	}
-	t.TotalDistance = distanceSum
 
-	return bson.Marshal((*RawRouteMovingTarget)(t))
+	if err := t.Route.Simplify(); err != nil {
+		return err
+	}
+
+	t.TotalDistance = t.Route.Distance()
+	return nil
 }
 
 func (t *RouteMovingTarget) Type() TargetType {
@@ -77,17 +267,53 @@ func (t *RouteMovingTarget) Type() TargetType {
 }
 
 func (t *RouteMovingTarget) Evaluate(ctx context.Context, acts []activities.Activity) (Progress, error) {
-	// Distance is the distance travelled by the user
+	if err := t.Resolve(ctx); err != nil {
+		return nil, err
+	}
+
+	checkpoints := t.Route.Checkpoints()
+	reached := make([]ReachedCheckpoint, 0, len(checkpoints))
+
+	// Distance is the weighted distance travelled by the user
 	var distance float64 = 0
-	for _, act := range acts {
-		if _, ok := activities.Moving[act.Type]; ok {
-			distance += act.Value
+	distances := map[activities.ActivityType]TypeDistance{}
+	for _, act := range Chronological(acts) {
+		if !allowsActivity(t.ActivityTypes, act.Type) {
+			continue
+		}
+
+		weighted := act.Value * t.Multiplier(act.Type)
+
+		// Checkpoints passed during the activity are assumed to be passed at a steady pace
+		for len(reached) < len(checkpoints) && checkpoints[len(reached)].Distance <= distance+weighted {
+			checkpoint := checkpoints[len(reached)]
+
+			fraction := 0.0
+			if weighted > 0 {
+				fraction = math.Max(checkpoint.Distance-distance, 0) / weighted
+			}
+
+			reached = append(reached, ReachedCheckpoint{
+				Checkpoint: checkpoint,
+				ReachedAt:  act.Start.Add(time.Duration(fraction * float64(act.End.Sub(act.Start)))),
+			})
 		}
+
+		distance += weighted
+
+		d := distances[act.Type]
+		d.Raw += act.Value
+		d.Weighted += weighted
+		distances[act.Type] = d
 	}
 
-	loc, err := t.Route.GetLocation(distance)
-	if err != nil {
-		return nil, ErrFindingLocation
+	// A route without waypoints hasn't been given yet, so there is nowhere to be along it
+	loc := locations.Location{}
+	if len(t.Route.Waypoints) > 0 {
+		var err error
+		if loc, err = t.Route.GetLocation(distance); err != nil {
+			return nil, ErrFindingLocation
+		}
 	}
 
 	var percent float64 = 0
@@ -95,10 +321,22 @@ func (t *RouteMovingTarget) Evaluate(ctx context.Context, acts []activities.Acti
 		percent = math.Min((distance/t.TotalDistance)*100, 100)
 	}
 
-	return RouteMovingTargetProgress{
-		Percent:         percent,
-		DistanceCovered: distance,
-		Location:        loc,
-	}, nil
+	progress := RouteMovingTargetProgress{
+		Percent:           percent,
+		DistanceCovered:   distance,
+		DistanceRemaining: math.Max(t.TotalDistance-distance, 0),
+		Location:          loc,
+		Distances:         distances,
+		Checkpoints:       reached,
+	}
+
+	if len(reached) < len(checkpoints) {
+		next := checkpoints[len(reached)]
+		progress.NextCheckpoint = &NextCheckpoint{
+			Checkpoint:        next,
+			DistanceRemaining: next.Distance - distance,
+		}
+	}
 
+	return progress, nil
 }
@@ -1,10 +1,18 @@
 package targets_test
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"math"
 	"testing"
+	"time"
 
+	"github.com/AustinBayley/activity_tracker_api/pkg/activities"
+	"github.com/AustinBayley/activity_tracker_api/pkg/locations"
+	"github.com/AustinBayley/activity_tracker_api/pkg/service"
 	"github.com/AustinBayley/activity_tracker_api/pkg/targets"
+	"go.mongodb.org/mongo-driver/v2/bson"
 )
 
 func TestMarshal(t *testing.T) {
@@ -36,3 +44,281 @@ func TestMarshal(t *testing.T) {
 		t.Error("expected RealTarget to be of type RouteMovingTarget")
 	}
 }
+
+func TestEvaluateWeighted(t *testing.T) {
+	target := targets.RouteMovingTarget{
+		BaseTarget: targets.BaseTarget{
+			TargetType: targets.RouteMovingTargetType,
+		},
+		Route: targets.Route{
+			Waypoints: locations.Waypoints{
+				{LatLng: locations.LatLng{Lat: 0, Lng: 0}},
+				{LatLng: locations.LatLng{Lat: 0, Lng: 1}},
+			},
+		},
+		TotalDistance: 100,
+		ActivityTypes: []activities.ActivityType{activities.Swimming, activities.Cycling},
+		Multipliers: map[activities.ActivityType]float64{
+			activities.Swimming: 4,
+			activities.Cycling:  0.3,
+		},
+	}
+
+	acts := []activities.Activity{
+		activities.NewActivity(activities.Swimming, 2),
+		activities.NewActivity(activities.Cycling, 10),
+		activities.NewActivity(activities.Cycling, 10),
+		activities.NewActivity(activities.Running, 10),
+	}
+
+	progress, err := target.Evaluate(context.Background(), acts)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	p, ok := progress.(targets.RouteMovingTargetProgress)
+	if !ok {
+		t.Fatalf("expected RouteMovingTargetProgress, got %T", progress)
+	}
+
+	if p.DistanceCovered != 14 {
+		t.Errorf("expected distance covered 14, got %f", p.DistanceCovered)
+	}
+
+	if _, ok := p.Distances[activities.Running]; ok {
+		t.Error("expected running to be excluded")
+	}
+
+	cycling := p.Distances[activities.Cycling]
+	if cycling.Raw != 20 || cycling.Weighted != 6 {
+		t.Errorf("expected cycling raw 20 and weighted 6, got %f and %f", cycling.Raw, cycling.Weighted)
+	}
+
+	swimming := p.Distances[activities.Swimming]
+	if swimming.Raw != 2 || swimming.Weighted != 8 {
+		t.Errorf("expected swimming raw 2 and weighted 8, got %f and %f", swimming.Raw, swimming.Weighted)
+	}
+}
+
+func TestEvaluateEmptyRoute(t *testing.T) {
+	target := targets.RouteMovingTarget{
+		BaseTarget: targets.BaseTarget{
+			TargetType: targets.RouteMovingTargetType,
+		},
+	}
+
+	progress, err := target.Evaluate(context.Background(), []activities.Activity{
+		activities.NewActivity(activities.Running, 10),
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	p, ok := progress.(targets.RouteMovingTargetProgress)
+	if !ok {
+		t.Fatalf("expected RouteMovingTargetProgress, got %T", progress)
+	}
+
+	if p.Percent != 0 || p.Location != (locations.Location{}) {
+		t.Errorf("expected 0%% at no location, got %f%% at %+v", p.Percent, p.Location)
+	}
+}
+
+func TestEvaluateCheckpoints(t *testing.T) {
+	waypoints := locations.Waypoints{
+		{LatLng: locations.LatLng{Lat: 0, Lng: 0}, Name: "Start", Checkpoint: true},
+		{LatLng: locations.LatLng{Lat: 0, Lng: 1}, Name: "Halfway", Checkpoint: true},
+		{LatLng: locations.LatLng{Lat: 0, Lng: 2}},
+		{LatLng: locations.LatLng{Lat: 0, Lng: 3}, Name: "Finish", Checkpoint: true},
+	}
+	leg := waypoints[0].DistanceTo(waypoints[1])
+
+	target := targets.RouteMovingTarget{
+		BaseTarget: targets.BaseTarget{
+			TargetType: targets.RouteMovingTargetType,
+		},
+		Route: targets.Route{
+			Waypoints: waypoints,
+		},
+		TotalDistance: leg * 3,
+	}
+
+	start := time.Date(2025, time.October, 6, 9, 0, 0, 0, time.UTC)
+	acts := []activities.Activity{
+		// Logged out of order, the halfway checkpoint is passed half way through the second activity
+		{Type: activities.Running, Value: leg / 2, Start: start.Add(24 * time.Hour), End: start.Add(26 * time.Hour)},
+		{Type: activities.Running, Value: leg * 3 / 4, Start: start, End: start.Add(time.Hour)},
+	}
+
+	progress, err := target.Evaluate(context.Background(), acts)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	p := progress.(targets.RouteMovingTargetProgress)
+
+	if len(p.Checkpoints) != 2 {
+		t.Fatalf("expected 2 checkpoints reached, got %d", len(p.Checkpoints))
+	}
+
+	if p.Checkpoints[0].Name != "Start" || !p.Checkpoints[0].ReachedAt.Equal(start) {
+		t.Errorf("expected start reached at %v, got %s at %v", start, p.Checkpoints[0].Name, p.Checkpoints[0].ReachedAt)
+	}
+
+	halfway := start.Add(25 * time.Hour)
+	if p.Checkpoints[1].Name != "Halfway" || p.Checkpoints[1].ReachedAt.Sub(halfway).Abs() > time.Second {
+		t.Errorf("expected halfway reached at %v, got %s at %v", halfway, p.Checkpoints[1].Name, p.Checkpoints[1].ReachedAt)
+	}
+
+	if p.NextCheckpoint == nil || p.NextCheckpoint.Name != "Finish" {
+		t.Fatalf("expected next checkpoint to be finish, got %+v", p.NextCheckpoint)
+	}
+
+	if remaining := leg * 7 / 4; math.Abs(p.NextCheckpoint.DistanceRemaining-remaining) > 1e-9 {
+		t.Errorf("expected %f km to next checkpoint, got %f", remaining, p.NextCheckpoint.DistanceRemaining)
+	}
+}
+
+func TestSimplify(t *testing.T) {
+	waypoints := locations.Waypoints{}
+	for i := range 101 {
+		waypoints = append(waypoints, locations.Waypoint{LatLng: locations.LatLng{Lat: 51, Lng: float64(i) * 0.001}})
+	}
+
+	target := targets.RouteMovingTarget{
+		BaseTarget: targets.BaseTarget{
+			TargetType: targets.RouteMovingTargetType,
+		},
+		Route: targets.Route{
+			Waypoints: waypoints,
+		},
+	}
+
+	// Saving doesn't change the route
+	if _, err := target.MarshalBSON(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if target.Route.Simplification != nil || len(target.Route.Waypoints) != 101 {
+		t.Fatalf("expected route not to be simplified when marshalled, got %d waypoints", len(target.Route.Waypoints))
+	}
+
+	if err := target.Simplify(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	s := target.Route.Simplification
+	if s == nil || s.OriginalWaypoints != 101 || len(target.Route.Waypoints) >= 101 {
+		t.Fatalf("expected route to be simplified, got %d waypoints and %+v", len(target.Route.Waypoints), s)
+	}
+
+	if target.TotalDistance != target.Route.Distance() {
+		t.Errorf("expected total distance %f to be the simplified distance, got %f", target.Route.Distance(), target.TotalDistance)
+	}
+
+	// Simplifying again keeps the original report
+	if err := target.Simplify(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if target.Route.Simplification.OriginalWaypoints != 101 {
+		t.Errorf("expected original waypoints to be kept, got %+v", target.Route.Simplification)
+	}
+}
+
+type stubResolver map[service.ID]targets.Route
+
+func (s stubResolver) ResolveRoute(_ context.Context, id service.ID) (*targets.Route, error) {
+	route, ok := s[id]
+	if !ok {
+		return nil, errors.New("route not found")
+	}
+	return &route, nil
+}
+
+func TestEvaluateReferencedRoute(t *testing.T) {
+	routeID := service.ID("bristol_bath")
+	route := targets.Route{
+		Waypoints: locations.Waypoints{
+			{LatLng: locations.LatLng{Lat: 51.45, Lng: -2.59}},
+			{LatLng: locations.LatLng{Lat: 51.38, Lng: -2.36}},
+		},
+	}
+
+	target := targets.RouteMovingTarget{
+		BaseTarget: targets.BaseTarget{
+			TargetType: targets.RouteMovingTargetType,
+		},
+		RouteID: &routeID,
+	}
+
+	acts := []activities.Activity{
+		{Type: activities.Running, Value: 5, Start: time.Now().Add(-time.Hour), End: time.Now()},
+	}
+
+	if _, err := target.Evaluate(context.Background(), acts); !errors.Is(err, targets.ErrResolvingRoute) {
+		t.Fatalf("expected %v without a resolver, got %v", targets.ErrResolvingRoute, err)
+	}
+
+	ctx := targets.WithRouteResolver(context.Background(), stubResolver{routeID: route})
+	progress, err := target.Evaluate(ctx, acts)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	p := progress.(targets.RouteMovingTargetProgress)
+	if total := route.Distance(); math.Abs(target.TotalDistance-total) > 1e-9 {
+		t.Errorf("expected total distance %f from the referenced route, got %f", total, target.TotalDistance)
+	}
+	if expected := 5 / target.TotalDistance * 100; math.Abs(p.Percent-expected) > 1e-9 {
+		t.Errorf("expected %f%%, got %f%%", expected, p.Percent)
+	}
+
+	// The referenced route is stored in the library, not with the target
+	data, err := target.MarshalBSON()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	stored := targets.RouteMovingTarget{}
+	if err := bson.Unmarshal(data, &stored); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if stored.RouteID == nil || *stored.RouteID != routeID || len(stored.Route.Waypoints) != 0 {
+		t.Errorf("expected only the route reference to be stored, got %+v", stored)
+	}
+}
+
+func TestMarshalBSONCells(t *testing.T) {
+	target := targets.RouteMovingTarget{
+		BaseTarget: targets.BaseTarget{
+			TargetType: targets.RouteMovingTargetType,
+		},
+		Route: targets.Route{
+			Waypoints: locations.Waypoints{
+				{LatLng: locations.LatLng{Lat: 51.45, Lng: -2.59}},
+				{LatLng: locations.LatLng{Lat: 51.38, Lng: -2.36}},
+				{LatLng: locations.LatLng{Lat: 51.75, Lng: -1.26}},
+			},
+		},
+	}
+
+	data, err := bson.Marshal(&target)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	stored := targets.RouteMovingTarget{}
+	if err := bson.Unmarshal(data, &stored); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	expected, err := target.Route.Waypoints.Cells()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(stored.Route.Cells) == 0 || len(stored.Route.Cells) != len(expected) {
+		t.Errorf("expected cells %v to be stored, got %v", expected, stored.Route.Cells)
+	}
+}
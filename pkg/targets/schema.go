@@ -0,0 +1,160 @@
+package targets
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	timeReflectType      = reflect.TypeOf(time.Time{})
+	rawTargetReflectType = reflect.TypeOf(RawTarget{})
+	targetReflectType    = reflect.TypeOf((*Target)(nil)).Elem()
+)
+
+// Schema returns a JSON Schema describing the configuration of the given target type,
+// derived from the json and validate tags of its fields.
+func Schema(targetType TargetType) (map[string]interface{}, bool) {
+	t := resolveType(targetType)
+	if t == nil {
+		return nil, false
+	}
+
+	schema := structSchema(reflect.TypeOf(t).Elem())
+	schema["$schema"] = "http://json-schema.org/draft-07/schema#"
+	schema["title"] = string(targetType)
+
+	// The discriminator can only take this target's type
+	if props, ok := schema["properties"].(map[string]interface{}); ok {
+		props["type"] = map[string]interface{}{
+			"type":  "string",
+			"const": string(targetType),
+		}
+	}
+	required, _ := schema["required"].([]string)
+	schema["required"] = append([]string{"type"}, required...)
+
+	return schema, true
+}
+
+// typeSchema returns the schema for a single Go type.
+func typeSchema(t reflect.Type) map[string]interface{} {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == timeReflectType:
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	case t == rawTargetReflectType, t == targetReflectType:
+		return map[string]interface{}{
+			"type":        "object",
+			"description": "a target of any registered type",
+			"required":    []string{"type"},
+		}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": typeSchema(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": typeSchema(t.Elem())}
+	case reflect.Struct:
+		return structSchema(t)
+	}
+
+	return map[string]interface{}{}
+}
+
+// structSchema returns an object schema for a struct, inlining embedded structs.
+func structSchema(t reflect.Type) map[string]interface{} {
+	properties := map[string]interface{}{}
+	required := []string{}
+
+	var addFields func(t reflect.Type)
+	addFields = func(t reflect.Type) {
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			tag := field.Tag.Get("json")
+			name, opts, _ := strings.Cut(tag, ",")
+
+			if field.Anonymous && name == "" {
+				addFields(field.Type)
+				continue
+			}
+
+			if !field.IsExported() || name == "-" {
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+
+			schema := typeSchema(field.Type)
+			if applyValidation(schema, field.Tag.Get("validate")) && !strings.Contains(opts, "omitempty") {
+				required = append(required, name)
+			}
+			properties[name] = schema
+		}
+	}
+	addFields(t)
+
+	schema := map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+
+	return schema
+}
+
+// applyValidation adds the constraints from a validate tag to a schema and reports whether
+// the field must be supplied.
+func applyValidation(schema map[string]interface{}, tag string) bool {
+	if tag == "" {
+		return false
+	}
+
+	required := true
+	for _, rule := range strings.Split(tag, ",") {
+		name, param, _ := strings.Cut(rule, "=")
+		switch name {
+		case "omitempty":
+			required = false
+		case "dive":
+			// Rules after dive apply to elements, which aren't described here
+			return required
+		case "oneof":
+			schema["enum"] = strings.Fields(param)
+		case "timezone":
+			schema["format"] = "timezone"
+		case "gt", "gte", "min":
+			n, err := strconv.ParseFloat(param, 64)
+			if err != nil {
+				continue
+			}
+			switch {
+			case schema["type"] == "array":
+				schema["minItems"] = int(n)
+			case name == "gt":
+				schema["exclusiveMinimum"] = n
+			default:
+				schema["minimum"] = n
+			}
+		}
+	}
+
+	return required
+}
@@ -0,0 +1,169 @@
+package targets
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/AustinBayley/activity_tracker_api/pkg/activities"
+)
+
+var (
+	_ Target   = (*StreakTarget)(nil)
+	_ Progress = (*StreakTargetProgress)(nil)
+)
+
+const (
+	StreakTargetType TargetType = "streakTarget"
+)
+
+var (
+	ErrInvalidTimezone = errors.New("invalid timezone")
+)
+
+// Period is a calendar period activities are grouped into.
+type Period string
+
+const (
+	Day  Period = "day"
+	Week Period = "week"
+)
+
+// Start returns the start of the period containing t in the given location.
+// Weeks are ISO weeks and start on a Monday.
+func (p Period) Start(t time.Time, loc *time.Location) time.Time {
+	t = t.In(loc)
+	start := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+	if p == Week {
+		// Monday is the first day of an ISO week
+		offset := (int(start.Weekday()) + 6) % 7
+		start = start.AddDate(0, 0, -offset)
+	}
+	return start
+}
+
+// Next returns the start of the period following the one starting at start.
+func (p Period) Next(start time.Time) time.Time {
+	if p == Week {
+		return start.AddDate(0, 0, 7)
+	}
+	return start.AddDate(0, 0, 1)
+}
+
+type StreakTargetProgress struct {
+	Percent            float64    `json:"percent" bson:"percent"`
+	CurrentStreak      int        `json:"currentStreak" bson:"currentStreak"`
+	BestStreak         int        `json:"bestStreak" bson:"bestStreak"`
+	CurrentStreakStart *time.Time `json:"currentStreakStart,omitempty" bson:"currentStreakStart,omitempty"`
+}
+
+func (s StreakTargetProgress) Percentage() float64 {
+	return s.Percent
+}
+
+// StreakTarget is a goal of consecutive days or weeks with qualifying activity.
+// A period qualifies when its matching activities add up to at least MinDistance km
+// or MinDuration minutes. If neither threshold is set, any matching activity qualifies.
+// Progress is measured by the best streak against Length.
+type StreakTarget struct {
+	BaseTarget    `bson:",inline"`
+	Length        int                       `json:"length" bson:"length" validate:"gt=0"`
+	Period        Period                    `json:"period" bson:"period" validate:"omitempty,oneof=day week"`
+	Timezone      string                    `json:"timezone,omitempty" bson:"timezone,omitempty" validate:"omitempty,timezone"`
+	MinDistance   float64                   `json:"minDistance,omitempty" bson:"minDistance,omitempty"`
+	MinDuration   float64                   `json:"minDuration,omitempty" bson:"minDuration,omitempty"`
+	ActivityTypes []activities.ActivityType `json:"activityTypes,omitempty" bson:"activityTypes,omitempty"`
+}
+
+func (t *StreakTarget) Type() TargetType {
+	return StreakTargetType
+}
+
+// Location returns the time zone day boundaries are evaluated in, defaulting to UTC.
+func (t *StreakTarget) Location() (*time.Location, error) {
+	return loadLocation(t.Timezone)
+}
+
+type periodTotal struct {
+	distance float64
+	duration float64
+}
+
+func (t *StreakTarget) qualifies(total periodTotal) bool {
+	if t.MinDistance <= 0 && t.MinDuration <= 0 {
+		return true
+	}
+	return (t.MinDistance > 0 && total.distance >= t.MinDistance) ||
+		(t.MinDuration > 0 && total.duration >= t.MinDuration)
+}
+
+func (t *StreakTarget) Evaluate(ctx context.Context, acts []activities.Activity) (Progress, error) {
+	loc, err := t.Location()
+	if err != nil {
+		return nil, err
+	}
+
+	period := t.Period
+	if period != Week {
+		period = Day
+	}
+
+	// Sum the matching activities into the period they started in
+	totals := map[time.Time]periodTotal{}
+	for _, act := range acts {
+		if !allowsActivity(t.ActivityTypes, act.Type) {
+			continue
+		}
+
+		start := period.Start(act.Start, loc)
+		total := totals[start]
+		total.distance += act.Value
+		total.duration += activityMinutes(act)
+		totals[start] = total
+	}
+
+	qualifying := make([]time.Time, 0, len(totals))
+	for start, total := range totals {
+		if t.qualifies(total) {
+			qualifying = append(qualifying, start)
+		}
+	}
+	sort.Slice(qualifying, func(i, j int) bool {
+		return qualifying[i].Before(qualifying[j])
+	})
+
+	var best, run int
+	var runStart time.Time
+	for i, start := range qualifying {
+		if i > 0 && period.Next(qualifying[i-1]).Equal(start) {
+			run++
+		} else {
+			run = 1
+			runStart = start
+		}
+		best = max(best, run)
+	}
+
+	progress := StreakTargetProgress{
+		BestStreak: best,
+	}
+
+	// The latest run is only current if it reaches this period or the one before,
+	// as the current period may not have had any activity yet.
+	if len(qualifying) > 0 {
+		last := qualifying[len(qualifying)-1]
+		now := period.Start(AsOf(ctx), loc)
+		if last.Equal(now) || period.Next(last).Equal(now) {
+			progress.CurrentStreak = run
+			progress.CurrentStreakStart = &runStart
+		}
+	}
+
+	if best > 0 && t.Length > 0 {
+		progress.Percent = math.Min((float64(best)/float64(t.Length))*100, 100)
+	}
+
+	return progress, nil
+}
@@ -0,0 +1,112 @@
+package targets_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/AustinBayley/activity_tracker_api/pkg/activities"
+	"github.com/AustinBayley/activity_tracker_api/pkg/targets"
+)
+
+func TestStreakTargetEvaluate(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/London")
+	if err != nil {
+		t.Skipf("timezone data unavailable: %v", err)
+	}
+
+	target := targets.StreakTarget{
+		BaseTarget: targets.BaseTarget{
+			TargetType: targets.StreakTargetType,
+		},
+		Length:      10,
+		Period:      targets.Day,
+		Timezone:    "Europe/London",
+		MinDistance: 5,
+	}
+
+	today := targets.Day.Start(time.Now(), loc)
+	day := func(offset int, hour int) time.Time {
+		return today.AddDate(0, 0, offset).Add(time.Duration(hour) * time.Hour)
+	}
+	run := func(start time.Time, value float64) activities.Activity {
+		return activities.Activity{Type: activities.Running, Value: value, Start: start, End: start.Add(30 * time.Minute)}
+	}
+
+	acts := []activities.Activity{
+		// Best streak of 4 days
+		run(day(-20, 9), 5),
+		run(day(-19, 9), 6),
+		run(day(-18, 9), 2),
+		run(day(-18, 18), 3),
+		run(day(-17, 9), 10),
+		// Too short to qualify
+		run(day(-16, 9), 1),
+		// Current streak of 2 days, ending yesterday
+		run(day(-2, 9), 5),
+		run(day(-1, 9), 5),
+	}
+
+	progress, err := target.Evaluate(context.Background(), acts)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	p, ok := progress.(targets.StreakTargetProgress)
+	if !ok {
+		t.Fatalf("expected StreakTargetProgress, got %T", progress)
+	}
+
+	if p.BestStreak != 4 {
+		t.Errorf("expected best streak 4, got %d", p.BestStreak)
+	}
+
+	if p.CurrentStreak != 2 {
+		t.Errorf("expected current streak 2, got %d", p.CurrentStreak)
+	}
+
+	if p.CurrentStreakStart == nil || !p.CurrentStreakStart.Equal(day(-2, 0)) {
+		t.Errorf("expected current streak to start %s, got %v", day(-2, 0), p.CurrentStreakStart)
+	}
+
+	if p.Percentage() != 40 {
+		t.Errorf("expected percentage 40, got %f", p.Percentage())
+	}
+}
+
+func TestStreakTargetWeeks(t *testing.T) {
+	target := targets.StreakTarget{
+		BaseTarget: targets.BaseTarget{
+			TargetType: targets.StreakTargetType,
+		},
+		Length: 4,
+		Period: targets.Week,
+	}
+
+	// 2025-10-05 is a Sunday and 2025-10-06 a Monday, so these fall in consecutive ISO weeks
+	sunday := time.Date(2025, time.October, 5, 12, 0, 0, 0, time.UTC)
+	monday := time.Date(2025, time.October, 6, 12, 0, 0, 0, time.UTC)
+	acts := []activities.Activity{
+		{Type: activities.Walking, Value: 1, Start: sunday, End: sunday.Add(time.Hour)},
+		{Type: activities.Walking, Value: 1, Start: monday, End: monday.Add(time.Hour)},
+	}
+
+	progress, err := target.Evaluate(context.Background(), acts)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if best := progress.(targets.StreakTargetProgress).BestStreak; best != 2 {
+		t.Errorf("expected best streak 2, got %d", best)
+	}
+}
+
+func TestStreakTargetInvalidTimezone(t *testing.T) {
+	target := targets.StreakTarget{
+		Timezone: "Not/AZone",
+	}
+
+	if _, err := target.Evaluate(context.Background(), nil); err != targets.ErrInvalidTimezone {
+		t.Errorf("expected ErrInvalidTimezone, got %v", err)
+	}
+}
@@ -4,7 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
-	"reflect"
+	"time"
 
 	"github.com/AustinBayley/activity_tracker_api/pkg/activities"
 	"go.mongodb.org/mongo-driver/v2/bson"
@@ -31,6 +31,52 @@ type Target interface {
 	Evaluate(context.Context, []activities.Activity) (Progress, error)
 }
 
+// Window is the period of time activities are evaluated over, e.g. the dates of a challenge.
+type Window struct {
+	Start time.Time `json:"start" bson:"start"`
+	End   time.Time `json:"end" bson:"end"`
+}
+
+// Contains reports whether t falls within the window. A zero start or end leaves that side open.
+func (w Window) Contains(t time.Time) bool {
+	if !w.Start.IsZero() && t.Before(w.Start) {
+		return false
+	}
+	if !w.End.IsZero() && t.After(w.End) {
+		return false
+	}
+	return true
+}
+
+type windowCtxKey struct{}
+
+// WithWindow returns a copy of ctx carrying the window targets should evaluate over.
+func WithWindow(ctx context.Context, w Window) context.Context {
+	return context.WithValue(ctx, windowCtxKey{}, w)
+}
+
+// WindowFromContext returns the evaluation window stored in ctx, if any.
+func WindowFromContext(ctx context.Context) (Window, bool) {
+	w, ok := ctx.Value(windowCtxKey{}).(Window)
+	return w, ok
+}
+
+type asOfCtxKey struct{}
+
+// WithAsOf returns a copy of ctx carrying the time targets should evaluate as of, e.g. when
+// replaying progress at the end of an earlier day.
+func WithAsOf(ctx context.Context, t time.Time) context.Context {
+	return context.WithValue(ctx, asOfCtxKey{}, t)
+}
+
+// AsOf returns the time stored in ctx to evaluate as of, or the current time if there isn't one.
+func AsOf(ctx context.Context) time.Time {
+	if t, ok := ctx.Value(asOfCtxKey{}).(time.Time); ok {
+		return t
+	}
+	return time.Now()
+}
+
 type BaseTarget struct {
 	TargetType TargetType `json:"type" bson:"type"`
 }
@@ -40,6 +86,22 @@ type RawTarget struct {
 	RealTarget Target `json:"-" bson:"-"`
 }
 
+// MarshalBSON marshals the underlying target, so a RawTarget can be embedded in other documents.
+func (t RawTarget) MarshalBSON() ([]byte, error) {
+	if t.RealTarget == nil {
+		return bson.Marshal(t.BaseTarget)
+	}
+	return bson.Marshal(t.RealTarget)
+}
+
+// MarshalJSON marshals the underlying target, so a RawTarget can be embedded in other documents.
+func (t RawTarget) MarshalJSON() ([]byte, error) {
+	if t.RealTarget == nil {
+		return json.Marshal(t.BaseTarget)
+	}
+	return json.Marshal(t.RealTarget)
+}
+
 func (t *RawTarget) UnmarshalBSON(b []byte) error {
 	// Get the type of target
 	raw := bson.Raw(b)
@@ -47,23 +109,18 @@ func (t *RawTarget) UnmarshalBSON(b []byte) error {
 		return ErrInvalidTarget
 	}
 
-	// Get a pointer to the target type
+	// Get a new instance of the target type
 	rt := resolveType(t.TargetType)
 	if rt == nil {
-		// If pointer is nil, an invalid target type was supplied
+		// If instance is nil, an invalid target type was supplied
 		return ErrInvalidTarget
 	}
 
-	// Get the type of the target type
-	tar := reflect.TypeOf(rt)
-	// Make a new pointer to the target type
-	v := reflect.New(tar.Elem())
-	// Unmarshal the bson into the new pointer
-	ptr := v.Interface()
-	if err := bson.Unmarshal(b, ptr); err != nil {
+	// Unmarshal the bson into the new instance
+	if err := bson.Unmarshal(b, rt); err != nil {
 		return ErrSyntaxError
 	}
-	t.RealTarget = ptr.(Target)
+	t.RealTarget = rt
 
 	return nil
 }
@@ -80,34 +137,50 @@ func (t *RawTarget) UnmarshalJSON(b []byte) error {
 	}
 	t.TargetType = TargetType(targetType.(string))
 
-	// Get a pointer to the target type
+	// Get a new instance of the target type
 	rt := resolveType(t.TargetType)
 	if rt == nil {
-		// If pointer is nil, an invalid target type was supplied
+		// If instance is nil, an invalid target type was supplied
 		return ErrInvalidTarget
 	}
 
-	// Get the type of the target type
-	tar := reflect.TypeOf(rt)
-	// Make a new pointer to the target type
-	v := reflect.New(tar.Elem())
-	// Unmarshal the bson into the new pointer
-	ptr := v.Interface()
-	if err := json.Unmarshal(b, ptr); err != nil {
+	// Unmarshal the json into the new instance
+	if err := json.Unmarshal(b, rt); err != nil {
 		return ErrSyntaxError
 	}
-	t.RealTarget = ptr.(Target)
+	t.RealTarget = rt
 
 	return nil
 }
 
-// resolveType returns a new instance of the target type based on the provided TargetType.
-func resolveType(targetType TargetType) Target {
-	var target Target
-	switch targetType {
-	case RouteMovingTargetType:
-		target = &RouteMovingTarget{}
+// allowsActivity reports whether an activity of the given type counts towards a target
+// restricted to the allowed types. An empty list falls back to every moving activity, and a
+// list containing Any allows every type.
+func allowsActivity(allowed []activities.ActivityType, actType activities.ActivityType) bool {
+	if len(allowed) == 0 {
+		_, ok := activities.Moving[actType]
+		return ok
+	}
+
+	for _, a := range allowed {
+		if a == activities.Any || a == actType {
+			return true
+		}
+	}
+
+	return false
+}
+
+// loadLocation returns the named time zone, defaulting to UTC.
+func loadLocation(timezone string) (*time.Location, error) {
+	if timezone == "" {
+		return time.UTC, nil
+	}
+
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, ErrInvalidTimezone
 	}
 
-	return target
+	return loc, nil
 }
@@ -0,0 +1,115 @@
+package targets
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/AustinBayley/activity_tracker_api/pkg/activities"
+)
+
+// Chronological returns a copy of the activities sorted by the time they finished.
+func Chronological(acts []activities.Activity) []activities.Activity {
+	sorted := slices.Clone(acts)
+	slices.SortStableFunc(sorted, func(a, b activities.Activity) int {
+		return a.End.Compare(b.End)
+	})
+	return sorted
+}
+
+// ReachedAt evaluates the activities against the target and returns the progress along with
+// the time it was first reached, i.e. when the activity that took the user to that percentage
+// finished. The time is zero if no progress has been made.
+// Progress is assumed to never decrease as activities are added.
+func ReachedAt(ctx context.Context, t Target, acts []activities.Activity) (Progress, time.Time, error) {
+	sorted := Chronological(acts)
+
+	final, err := t.Evaluate(ctx, sorted)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	if final.Percentage() <= 0 || len(sorted) == 0 {
+		return final, time.Time{}, nil
+	}
+
+	// Find the shortest prefix of activities which reaches the final percentage
+	lo, hi := 1, len(sorted)
+	for lo < hi {
+		mid := (lo + hi) / 2
+
+		p, err := t.Evaluate(ctx, sorted[:mid])
+		if err != nil {
+			return nil, time.Time{}, err
+		}
+
+		if p.Percentage() >= final.Percentage() {
+			hi = mid
+		} else {
+			lo = mid + 1
+		}
+	}
+
+	return final, sorted[lo-1].End, nil
+}
+
+// Point is the cumulative progress at the end of an interval.
+type Point struct {
+	Start    time.Time `json:"start" bson:"start"`
+	End      time.Time `json:"end" bson:"end"`
+	Progress Progress  `json:"progress" bson:"progress"`
+}
+
+// History replays the activities in the order they finished and returns the cumulative progress
+// at the end of each day or week in the given location. Intervals run from the start of the
+// window (or the first activity) to the end of the window or now, whichever is sooner, where now
+// is the time given to WithAsOf if there is one.
+func History(ctx context.Context, t Target, acts []activities.Activity, period Period, loc *time.Location, window Window) ([]Point, error) {
+	sorted := Chronological(acts)
+	points := make([]Point, 0)
+
+	from := window.Start
+	if from.IsZero() {
+		if len(sorted) == 0 {
+			return points, nil
+		}
+		from = sorted[0].Start
+	}
+
+	to := AsOf(ctx)
+	if !window.End.IsZero() && window.End.Before(to) {
+		to = window.End
+	}
+
+	ctx = WithWindow(ctx, window)
+
+	n := 0
+	for start := period.Start(from, loc); start.Before(to); start = period.Next(start) {
+		end := period.Next(start)
+
+		// Evaluate as of the last moment of the interval, so targets which depend on the
+		// current time, such as streaks, report what they did then
+		asOf := end.Add(-time.Nanosecond)
+		if asOf.After(to) {
+			asOf = to
+		}
+
+		// Include every activity which had finished by then
+		for n < len(sorted) && !sorted[n].End.After(asOf) {
+			n++
+		}
+
+		p, err := t.Evaluate(WithAsOf(ctx, asOf), sorted[:n])
+		if err != nil {
+			return nil, err
+		}
+
+		points = append(points, Point{
+			Start:    start,
+			End:      end,
+			Progress: p,
+		})
+	}
+
+	return points, nil
+}
@@ -0,0 +1,179 @@
+package targets_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/AustinBayley/activity_tracker_api/pkg/activities"
+	"github.com/AustinBayley/activity_tracker_api/pkg/targets"
+)
+
+func TestReachedAt(t *testing.T) {
+	target := &targets.DistanceTarget{
+		BaseTarget: targets.BaseTarget{
+			TargetType: targets.DistanceTargetType,
+		},
+		Distance: 20,
+	}
+
+	start := time.Date(2025, time.October, 1, 9, 0, 0, 0, time.UTC)
+	run := func(day int, value float64) activities.Activity {
+		s := start.AddDate(0, 0, day)
+		return activities.Activity{Type: activities.Running, Value: value, Start: s, End: s.Add(time.Hour)}
+	}
+
+	// Out of order, the target is completed by the run on day 3
+	acts := []activities.Activity{
+		run(5, 10),
+		run(0, 10),
+		run(3, 15),
+	}
+
+	progress, reached, err := targets.ReachedAt(context.Background(), target, acts)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if progress.Percentage() != 100 {
+		t.Errorf("expected percentage 100, got %f", progress.Percentage())
+	}
+
+	if expected := start.AddDate(0, 0, 3).Add(time.Hour); !reached.Equal(expected) {
+		t.Errorf("expected reached at %s, got %s", expected, reached)
+	}
+
+	_, reached, err = targets.ReachedAt(context.Background(), target, nil)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if !reached.IsZero() {
+		t.Errorf("expected zero reached time without activities, got %s", reached)
+	}
+}
+
+func TestHistory(t *testing.T) {
+	target := &targets.DistanceTarget{
+		BaseTarget: targets.BaseTarget{
+			TargetType: targets.DistanceTargetType,
+		},
+		Distance: 100,
+	}
+
+	start := time.Date(2025, time.October, 1, 0, 0, 0, 0, time.UTC)
+	run := func(day int, value float64) activities.Activity {
+		s := start.AddDate(0, 0, day).Add(9 * time.Hour)
+		return activities.Activity{Type: activities.Running, Value: value, Start: s, End: s.Add(time.Hour)}
+	}
+
+	acts := []activities.Activity{
+		run(2, 10),
+		run(0, 10),
+		run(2, 5),
+		run(4, 20),
+	}
+
+	window := targets.Window{
+		Start: start,
+		End:   start.AddDate(0, 0, 5).Add(-time.Second),
+	}
+
+	points, err := targets.History(context.Background(), target, acts, targets.Day, time.UTC, window)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	expected := []float64{10, 10, 25, 25, 45}
+	if len(points) != len(expected) {
+		t.Fatalf("expected %d points, got %d", len(expected), len(points))
+	}
+
+	for i, p := range points {
+		covered := p.Progress.(targets.DistanceTargetProgress).DistanceCovered
+		if covered != expected[i] {
+			t.Errorf("day %d: expected distance covered %f, got %f", i, expected[i], covered)
+		}
+
+		if !p.Start.Equal(start.AddDate(0, 0, i)) {
+			t.Errorf("day %d: expected start %s, got %s", i, start.AddDate(0, 0, i), p.Start)
+		}
+	}
+}
+
+func TestHistoryAsOf(t *testing.T) {
+	streak := &targets.StreakTarget{
+		BaseTarget: targets.BaseTarget{
+			TargetType: targets.StreakTargetType,
+		},
+		Length: 7,
+		Period: targets.Day,
+	}
+
+	frequency := &targets.FrequencyTarget{
+		BaseTarget: targets.BaseTarget{
+			TargetType: targets.FrequencyTargetType,
+		},
+		Sessions: 1,
+		Weeks:    4,
+	}
+
+	// A Monday, so weeks of the frequency target start with the window
+	start := time.Date(2025, time.October, 6, 0, 0, 0, 0, time.UTC)
+	run := func(day int) activities.Activity {
+		s := start.AddDate(0, 0, day).Add(9 * time.Hour)
+		return activities.Activity{Type: activities.Running, Value: 5, Start: s, End: s.Add(time.Hour)}
+	}
+
+	acts := []activities.Activity{run(0), run(1), run(2)}
+	window := targets.Window{
+		Start: start,
+		End:   start.AddDate(0, 0, 6).Add(-time.Second),
+	}
+
+	points, err := targets.History(context.Background(), streak, acts, targets.Day, time.UTC, window)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	// The streak is current until a whole day passes without a run
+	for i, expected := range []int{1, 2, 3, 3, 0, 0} {
+		current := points[i].Progress.(targets.StreakTargetProgress).CurrentStreak
+		if current != expected {
+			t.Errorf("day %d: expected current streak %d, got %d", i, expected, current)
+		}
+	}
+
+	// Over two weeks, only weeks which had started by the end of each day are listed
+	window.End = start.AddDate(0, 0, 14).Add(-time.Second)
+	points, err = targets.History(context.Background(), frequency, acts, targets.Day, time.UTC, window)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(points) != 14 {
+		t.Fatalf("expected 14 points, got %d", len(points))
+	}
+
+	for i, p := range points {
+		expected := 1 + i/7
+		if weeks := p.Progress.(targets.FrequencyTargetProgress).Weeks; len(weeks) != expected {
+			t.Errorf("day %d: expected %d weeks, got %d", i, expected, len(weeks))
+		}
+	}
+
+	// History as of a time within the window stops there, leaving out later activities
+	asOf := start.AddDate(0, 0, 1).Add(12 * time.Hour)
+	points, err = targets.History(targets.WithAsOf(context.Background(), asOf), streak, acts, targets.Day, time.UTC, window)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(points) != 2 {
+		t.Fatalf("expected 2 points up to %s, got %d", asOf, len(points))
+	}
+
+	if current := points[1].Progress.(targets.StreakTargetProgress).CurrentStreak; current != 2 {
+		t.Errorf("expected current streak 2 as of %s, got %d", asOf, current)
+	}
+}
@@ -0,0 +1,128 @@
+package tracks
+
+import (
+	"math"
+)
+
+const (
+	// MinMovingSpeed is the speed in km/h below which time between points isn't counted as moving,
+	// so pauses that a device kept recording through are left out of the moving time.
+	MinMovingSpeed = 1.8
+	// SplitDistance is the length of a split in km.
+	SplitDistance = 1.0
+	// ElevationNoise is the rise in metres that must be climbed before it counts towards the
+	// elevation gain, so small jitters in recorded elevation don't add up.
+	ElevationNoise = 1.0
+)
+
+// Split is the time taken over a kilometre of a track.
+type Split struct {
+	// Distance is the length of the split in km. It is SplitDistance for all but the last split.
+	Distance float64 `json:"distance" bson:"distance"`
+	// Time is the elapsed time of the split in minutes.
+	Time float64 `json:"time" bson:"time"`
+	// Pace is the time taken per km in minutes.
+	Pace float64 `json:"pace" bson:"pace"`
+}
+
+// Metrics are measurements derived from the points of a track. Times are in minutes, distances in
+// km and elevation in metres.
+type Metrics struct {
+	Distance    float64 `json:"distance" bson:"distance"`
+	ElapsedTime float64 `json:"elapsedTime" bson:"elapsedTime"`
+	// MovingTime is the time spent moving faster than MinMovingSpeed.
+	MovingTime float64 `json:"movingTime" bson:"movingTime"`
+	// AverageSpeed is the distance over the moving time in km/h.
+	AverageSpeed float64 `json:"averageSpeed" bson:"averageSpeed"`
+	// AveragePace is the moving time per km in minutes.
+	AveragePace   float64 `json:"averagePace" bson:"averagePace"`
+	ElevationGain float64 `json:"elevationGain" bson:"elevationGain"`
+	Splits        []Split `json:"splits" bson:"splits"`
+}
+
+// Metrics measures the points. Points without a time don't count towards any of the times.
+func (p Points) Metrics() Metrics {
+	m := Metrics{
+		Splits:        []Split{},
+		ElevationGain: p.ElevationGain(),
+	}
+
+	split := Split{}
+	for i := 1; i < len(p); i++ {
+		distance := p[i-1].Waypoint().DistanceTo(p[i].Waypoint())
+
+		var elapsed float64 = 0
+		if !p[i-1].Time.IsZero() && p[i].Time.After(p[i-1].Time) {
+			elapsed = p[i].Time.Sub(p[i-1].Time).Minutes()
+		}
+
+		m.Distance += distance
+		m.ElapsedTime += elapsed
+		if elapsed > 0 && distance/(elapsed/60) >= MinMovingSpeed {
+			m.MovingTime += elapsed
+		}
+
+		// Share the time between splits in proportion to the distance covered in each
+		for distance > 0 {
+			step := math.Min(distance, SplitDistance-split.Distance)
+			share := elapsed * step / distance
+
+			split.Distance += step
+			split.Time += share
+			distance -= step
+			elapsed -= share
+
+			if SplitDistance-split.Distance < 1e-9 {
+				m.Splits = append(m.Splits, split.withPace())
+				split = Split{}
+			}
+		}
+		// Time spent without moving belongs to the split it was spent in
+		split.Time += elapsed
+	}
+
+	if split.Distance > 1e-9 {
+		m.Splits = append(m.Splits, split.withPace())
+	}
+
+	if m.MovingTime > 0 {
+		m.AverageSpeed = m.Distance / (m.MovingTime / 60)
+	}
+
+	if m.Distance > 0 {
+		m.AveragePace = m.MovingTime / m.Distance
+	}
+
+	return m
+}
+
+func (s Split) withPace() Split {
+	s.Pace = s.Time / s.Distance
+	return s
+}
+
+// ElevationGain returns the total climb in metres between points with a recorded elevation.
+func (p Points) ElevationGain() float64 {
+	var (
+		gain float64 = 0
+		last *float64
+	)
+
+	// Elevation only counts once it has changed by more than the noise from the last that did
+	for _, point := range p {
+		if point.Elevation == nil {
+			continue
+		}
+
+		e := *point.Elevation
+		switch {
+		case last == nil || *last-e >= ElevationNoise:
+			last = &e
+		case e-*last >= ElevationNoise:
+			gain += e - *last
+			last = &e
+		}
+	}
+
+	return gain
+}
@@ -0,0 +1,114 @@
+package tracks_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/AustinBayley/activity_tracker_api/pkg/locations"
+	"github.com/AustinBayley/activity_tracker_api/pkg/tracks"
+)
+
+// kmOfLongitude is the length of a degree of longitude at 51.45 degrees north.
+var kmOfLongitude = math.Pi / 180 * 6371.0088 * math.Cos(51.45*math.Pi/180)
+
+// eastward returns points heading east along 51.45 degrees north, each at a distance in km and
+// a number of minutes from the start.
+func eastward(start time.Time, legs ...[2]float64) tracks.Points {
+	points := tracks.Points{}
+	for _, l := range legs {
+		points = append(points, tracks.Point{
+			Time:   start.Add(time.Duration(l[1] * float64(time.Minute))),
+			LatLng: locations.LatLng{Lat: 51.45, Lng: -2.6 + l[0]/kmOfLongitude},
+		})
+	}
+	return points
+}
+
+func TestMetrics(t *testing.T) {
+	start := time.Date(2025, 10, 6, 9, 0, 0, 0, time.UTC)
+
+	// 2.5 km at 5 minutes per km, with a 10 minute stop after 1.5 km
+	points := eastward(start,
+		[2]float64{0, 0},
+		[2]float64{1.5, 7.5},
+		[2]float64{1.5, 17.5},
+		[2]float64{2.5, 22.5},
+	)
+
+	m := points.Metrics()
+
+	for _, tt := range []struct {
+		name     string
+		value    float64
+		expected float64
+	}{
+		{"distance", m.Distance, 2.5},
+		{"elapsed time", m.ElapsedTime, 22.5},
+		{"moving time", m.MovingTime, 12.5},
+		{"average speed", m.AverageSpeed, 12},
+		{"average pace", m.AveragePace, 5},
+	} {
+		if math.Abs(tt.value-tt.expected) > 0.001 {
+			t.Errorf("expected %s of %f, got %f", tt.name, tt.expected, tt.value)
+		}
+	}
+
+	if len(m.Splits) != 3 {
+		t.Fatalf("expected 3 splits, got %d", len(m.Splits))
+	}
+
+	// The stop falls in the second split, and the last split is half a km
+	for i, expected := range []tracks.Split{
+		{Distance: 1, Time: 5, Pace: 5},
+		{Distance: 1, Time: 15, Pace: 15},
+		{Distance: 0.5, Time: 2.5, Pace: 5},
+	} {
+		s := m.Splits[i]
+		if math.Abs(s.Distance-expected.Distance) > 0.001 ||
+			math.Abs(s.Time-expected.Time) > 0.001 ||
+			math.Abs(s.Pace-expected.Pace) > 0.001 {
+			t.Errorf("expected split %d to be %+v, got %+v", i+1, expected, s)
+		}
+	}
+}
+
+func TestMetricsWithoutTimes(t *testing.T) {
+	points := eastward(time.Time{}, [2]float64{0, 0}, [2]float64{1.5, 0})
+	for i := range points {
+		points[i].Time = time.Time{}
+	}
+
+	m := points.Metrics()
+	if math.Abs(m.Distance-1.5) > 0.001 {
+		t.Errorf("expected distance of 1.5 km, got %f", m.Distance)
+	}
+
+	if m.MovingTime != 0 || m.AverageSpeed != 0 || m.AveragePace != 0 {
+		t.Errorf("expected no times, got %+v", m)
+	}
+
+	if len(m.Splits) != 2 {
+		t.Errorf("expected 2 splits, got %d", len(m.Splits))
+	}
+}
+
+func TestElevationGain(t *testing.T) {
+	elevations := []*float64{}
+	for _, e := range []float64{10, 12, 11.5, 15, 13, 13.5, 11, 14} {
+		e := e
+		elevations = append(elevations, &e)
+	}
+	// Points without an elevation are skipped
+	elevations = append(elevations[:3], append([]*float64{nil}, elevations[3:]...)...)
+
+	points := tracks.Points{}
+	for _, e := range elevations {
+		points = append(points, tracks.Point{Elevation: e})
+	}
+
+	// 10 to 15 and 11 to 14, ignoring the half metre wobbles
+	if gain := points.ElevationGain(); gain != 8 {
+		t.Errorf("expected elevation gain of 8 m, got %f", gain)
+	}
+}
@@ -0,0 +1,308 @@
+package tracks
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"math"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/AustinBayley/activity_tracker_api/pkg/activities"
+	"github.com/AustinBayley/activity_tracker_api/pkg/locations"
+	"github.com/AustinBayley/activity_tracker_api/pkg/service"
+	"github.com/muktihari/fit/decoder"
+	"github.com/muktihari/fit/profile/basetype"
+	"github.com/muktihari/fit/profile/filedef"
+	"github.com/muktihari/fit/profile/typedef"
+)
+
+type Format string
+
+const (
+	GPX Format = "gpx"
+	TCX Format = "tcx"
+	FIT Format = "fit"
+)
+
+var (
+	ErrUnknownFormat = errors.New("unknown file format")
+	ErrInvalidFile   = errors.New("invalid activity file")
+	ErrNoTimes       = errors.New("activity file has no times")
+)
+
+// Recording is an activity read from a file exported by a device.
+type Recording struct {
+	// Type is the activity type named by the file, if it is one we recognise.
+	Type activities.ActivityType
+	// Points are the timestamped positions recorded. Samples without a position are left out.
+	Points Points
+	Start  time.Time
+	End    time.Time
+	// RecordedDistance is the distance in km reported by the device, if any. It is only used for
+	// activities recorded without positions, such as treadmill runs.
+	RecordedDistance float64
+}
+
+// Distance returns the distance in km along the recorded points, or the recorded distance if
+// there are no points.
+func (r Recording) Distance() float64 {
+	if len(r.Points) > 1 {
+		return r.Points.Distance()
+	}
+	return r.RecordedDistance
+}
+
+// Activity returns the recording as an activity of the user.
+func (r Recording) Activity(userID service.ID) activities.Activity {
+	return activities.Activity{
+		UserID: userID,
+		Type:   r.Type,
+		Value:  r.Distance(),
+		Start:  r.Start,
+		End:    r.End,
+	}
+}
+
+// extend widens the recording's start and end to include t.
+func (r *Recording) extend(t time.Time) {
+	if t.IsZero() {
+		return
+	}
+	if r.Start.IsZero() || t.Before(r.Start) {
+		r.Start = t
+	}
+	if t.After(r.End) {
+		r.End = t
+	}
+}
+
+// ActivityType returns the activity type named by a sport in an activity file, e.g. "Running",
+// "biking" or "open_water_swimming", or an empty type if it isn't recognised.
+func ActivityType(sport string) activities.ActivityType {
+	sport = strings.ToLower(sport)
+	switch {
+	case strings.Contains(sport, "run"):
+		return activities.Running
+	case strings.Contains(sport, "cycl"), strings.Contains(sport, "bik"), strings.Contains(sport, "ride"):
+		return activities.Cycling
+	case strings.Contains(sport, "swim"):
+		return activities.Swimming
+	case strings.Contains(sport, "walk"), strings.Contains(sport, "hik"):
+		return activities.Walking
+	}
+	return ""
+}
+
+// DetectFormat returns the format of an activity file from its name or, failing that, its content.
+func DetectFormat(filename string, data []byte) (Format, error) {
+	switch Format(strings.TrimPrefix(strings.ToLower(filepath.Ext(filename)), ".")) {
+	case GPX:
+		return GPX, nil
+	case TCX:
+		return TCX, nil
+	case FIT:
+		return FIT, nil
+	}
+
+	// FIT files have a data type of ".FIT" in their header
+	if len(data) >= 12 && string(data[8:12]) == ".FIT" {
+		return FIT, nil
+	}
+
+	d := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		token, err := d.Token()
+		if err != nil {
+			return "", ErrUnknownFormat
+		}
+
+		if start, ok := token.(xml.StartElement); ok {
+			switch start.Name.Local {
+			case "gpx":
+				return GPX, nil
+			case "TrainingCenterDatabase":
+				return TCX, nil
+			}
+			return "", ErrUnknownFormat
+		}
+	}
+}
+
+// Parse reads an activity file of the given format.
+func Parse(data []byte, format Format) (*Recording, error) {
+	var (
+		recording *Recording
+		err       error
+	)
+
+	switch format {
+	case GPX:
+		recording, err = ParseGPX(data)
+	case TCX:
+		recording, err = ParseTCX(data)
+	case FIT:
+		recording, err = ParseFIT(data)
+	default:
+		return nil, ErrUnknownFormat
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if !recording.End.After(recording.Start) {
+		return nil, ErrNoTimes
+	}
+
+	return recording, nil
+}
+
+// ParseGPX reads the tracks of a GPX file.
+func ParseGPX(data []byte) (*Recording, error) {
+	gpx, err := locations.ParseGPX(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidFile, err)
+	}
+
+	recording := Recording{}
+	for _, t := range gpx.Tracks {
+		if recording.Type == "" {
+			recording.Type = ActivityType(t.Type)
+		}
+	}
+
+	for _, p := range gpx.Points() {
+		point := Point{
+			LatLng:    locations.LatLng{Lat: p.Lat, Lng: p.Lon},
+			Elevation: p.Elevation,
+		}
+		if p.Time != nil {
+			point.Time = *p.Time
+			recording.extend(point.Time)
+		}
+		recording.Points = append(recording.Points, point)
+	}
+
+	return &recording, nil
+}
+
+// tcx is a Training Center XML document, limited to its activities.
+type tcx struct {
+	XMLName    xml.Name `xml:"TrainingCenterDatabase"`
+	Activities []struct {
+		Sport string `xml:"Sport,attr"`
+		Laps  []struct {
+			StartTime        time.Time `xml:"StartTime,attr"`
+			TotalTimeSeconds float64   `xml:"TotalTimeSeconds"`
+			DistanceMeters   float64   `xml:"DistanceMeters"`
+			Trackpoints      []struct {
+				Time     time.Time `xml:"Time"`
+				Position *struct {
+					Lat float64 `xml:"LatitudeDegrees"`
+					Lng float64 `xml:"LongitudeDegrees"`
+				} `xml:"Position"`
+				AltitudeMeters *float64 `xml:"AltitudeMeters"`
+			} `xml:"Track>Trackpoint"`
+		} `xml:"Lap"`
+	} `xml:"Activities>Activity"`
+}
+
+// ParseTCX reads the activities of a Training Center XML file, as exported by Garmin devices.
+func ParseTCX(data []byte) (*Recording, error) {
+	doc := tcx{}
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidFile, err)
+	}
+
+	recording := Recording{}
+	for _, activity := range doc.Activities {
+		if recording.Type == "" {
+			recording.Type = ActivityType(activity.Sport)
+		}
+
+		for _, lap := range activity.Laps {
+			recording.RecordedDistance += lap.DistanceMeters / 1000
+			recording.extend(lap.StartTime)
+			if !lap.StartTime.IsZero() {
+				recording.extend(lap.StartTime.Add(time.Duration(lap.TotalTimeSeconds * float64(time.Second))))
+			}
+
+			for _, tp := range lap.Trackpoints {
+				recording.extend(tp.Time)
+				if tp.Position == nil {
+					continue
+				}
+
+				point := Point{
+					Time:      tp.Time,
+					LatLng:    locations.LatLng{Lat: tp.Position.Lat, Lng: tp.Position.Lng},
+					Elevation: tp.AltitudeMeters,
+				}
+				if !point.LatLng.Valid() {
+					return nil, fmt.Errorf("%w: point %v out of range", ErrInvalidFile, point.LatLng)
+				}
+				recording.Points = append(recording.Points, point)
+			}
+		}
+	}
+
+	return &recording, nil
+}
+
+// ParseFIT reads the sessions and records of a FIT activity file.
+func ParseFIT(data []byte) (*Recording, error) {
+	file, err := decoder.New(bytes.NewReader(data)).Decode()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidFile, err)
+	}
+
+	activity := filedef.NewActivity(file.Messages...)
+	if activity.FileId.Type != typedef.FileActivity {
+		return nil, fmt.Errorf("%w: fit file is a %s file, not an activity", ErrInvalidFile, activity.FileId.Type)
+	}
+
+	recording := Recording{}
+	for _, session := range activity.Sessions {
+		if recording.Type == "" {
+			recording.Type = ActivityType(session.Sport.String())
+		}
+
+		if d := session.TotalDistanceScaled(); !math.IsNaN(d) {
+			recording.RecordedDistance += d / 1000
+		}
+
+		recording.extend(session.StartTime)
+		if elapsed := session.TotalElapsedTimeScaled(); !session.StartTime.IsZero() && !math.IsNaN(elapsed) {
+			recording.extend(session.StartTime.Add(time.Duration(elapsed * float64(time.Second))))
+		}
+	}
+
+	for _, record := range activity.Records {
+		recording.extend(record.Timestamp)
+		if record.PositionLat == basetype.Sint32Invalid || record.PositionLong == basetype.Sint32Invalid {
+			continue
+		}
+
+		point := Point{
+			Time: record.Timestamp,
+			LatLng: locations.LatLng{
+				Lat: record.PositionLatDegrees(),
+				Lng: record.PositionLongDegrees(),
+			},
+		}
+
+		elevation := record.EnhancedAltitudeScaled()
+		if math.IsNaN(elevation) {
+			elevation = record.AltitudeScaled()
+		}
+		if !math.IsNaN(elevation) {
+			point.Elevation = &elevation
+		}
+
+		recording.Points = append(recording.Points, point)
+	}
+
+	return &recording, nil
+}
@@ -0,0 +1,225 @@
+package tracks_test
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/AustinBayley/activity_tracker_api/pkg/activities"
+	"github.com/AustinBayley/activity_tracker_api/pkg/tracks"
+	"github.com/muktihari/fit/encoder"
+	"github.com/muktihari/fit/profile/filedef"
+	"github.com/muktihari/fit/profile/mesgdef"
+	"github.com/muktihari/fit/profile/typedef"
+)
+
+// 0.02 degrees of longitude at 51.45 degrees north
+var harbourside = 0.02 * math.Pi / 180 * 6371.0088 * math.Cos(51.45*math.Pi/180)
+
+func readFile(t *testing.T, name string) []byte {
+	t.Helper()
+
+	data, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatalf("failed to read %s: %v", name, err)
+	}
+
+	return data
+}
+
+// encodeFIT writes a FIT activity with a session of the sport and a record for each position,
+// a minute apart. A nil position is a record without one.
+func encodeFIT(t *testing.T, fileType typedef.File, sport typedef.Sport, start time.Time, positions ...*[2]float64) []byte {
+	t.Helper()
+
+	activity := filedef.NewActivity()
+	activity.FileId = *mesgdef.NewFileId(nil).SetType(fileType).SetTimeCreated(start)
+	activity.Sessions = append(activity.Sessions, mesgdef.NewSession(nil).
+		SetTimestamp(start).
+		SetStartTime(start).
+		SetSport(sport).
+		SetTotalElapsedTimeScaled(float64(len(positions))*60).
+		SetTotalDistanceScaled(5000))
+
+	for i, p := range positions {
+		record := mesgdef.NewRecord(nil).SetTimestamp(start.Add(time.Duration(i) * time.Minute))
+		if p != nil {
+			record.SetPositionLatDegrees(p[0]).SetPositionLongDegrees(p[1]).SetEnhancedAltitudeScaled(20)
+		}
+		activity.Records = append(activity.Records, record)
+	}
+
+	fit := activity.ToFIT(nil)
+	buf := bytes.Buffer{}
+	if err := encoder.New(&buf).Encode(&fit); err != nil {
+		t.Fatalf("failed to encode fit file: %v", err)
+	}
+
+	return buf.Bytes()
+}
+
+func TestDetectFormat(t *testing.T) {
+	start := time.Date(2025, 10, 8, 6, 0, 0, 0, time.UTC)
+	fit := encodeFIT(t, typedef.FileActivity, typedef.SportRunning, start)
+
+	for _, tt := range []struct {
+		name     string
+		filename string
+		data     []byte
+		expected tracks.Format
+		err      error
+	}{
+		{"gpx extension", "morning.GPX", nil, tracks.GPX, nil},
+		{"tcx extension", "morning.tcx", nil, tracks.TCX, nil},
+		{"fit extension", "morning.fit", nil, tracks.FIT, nil},
+		{"gpx content", "", readFile(t, "testdata/run.gpx"), tracks.GPX, nil},
+		{"tcx content", "upload", readFile(t, "testdata/ride.tcx"), tracks.TCX, nil},
+		{"fit content", "", fit, tracks.FIT, nil},
+		{"other xml", "", []byte(`<?xml version="1.0"?><kml></kml>`), "", tracks.ErrUnknownFormat},
+		{"not a file", "", []byte("hello"), "", tracks.ErrUnknownFormat},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			format, err := tracks.DetectFormat(tt.filename, tt.data)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("expected error %v, got %v", tt.err, err)
+			}
+
+			if format != tt.expected {
+				t.Errorf("expected format %q, got %q", tt.expected, format)
+			}
+		})
+	}
+}
+
+func TestParseGPX(t *testing.T) {
+	recording, err := tracks.Parse(readFile(t, "testdata/run.gpx"), tracks.GPX)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if recording.Type != activities.Running {
+		t.Errorf("expected type %s, got %s", activities.Running, recording.Type)
+	}
+
+	if len(recording.Points) != 5 {
+		t.Fatalf("expected 5 points, got %d", len(recording.Points))
+	}
+
+	if d := recording.End.Sub(recording.Start); d != 8*time.Minute {
+		t.Errorf("expected activity to last 8 minutes, got %v", d)
+	}
+
+	if d := recording.Distance(); math.Abs(d-harbourside) > 0.01 {
+		t.Errorf("expected distance of %f km, got %f", harbourside, d)
+	}
+}
+
+func TestParseTCX(t *testing.T) {
+	recording, err := tracks.Parse(readFile(t, "testdata/ride.tcx"), tracks.TCX)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if recording.Type != activities.Cycling {
+		t.Errorf("expected type %s, got %s", activities.Cycling, recording.Type)
+	}
+
+	// The trackpoint without a position is left out
+	if len(recording.Points) != 2 {
+		t.Fatalf("expected 2 points, got %d", len(recording.Points))
+	}
+
+	if e := recording.Points[1].Elevation; e == nil || *e != 14 {
+		t.Errorf("expected elevation of 14, got %v", e)
+	}
+
+	// The lap lasts longer than its last trackpoint
+	if d := recording.End.Sub(recording.Start); d != 10*time.Minute {
+		t.Errorf("expected activity to last 10 minutes, got %v", d)
+	}
+
+	// Distance is measured along the points rather than taken from the lap
+	if d := recording.Distance(); math.Abs(d-harbourside) > 0.01 {
+		t.Errorf("expected distance of %f km, got %f", harbourside, d)
+	}
+}
+
+func TestParseFIT(t *testing.T) {
+	start := time.Date(2025, 10, 8, 6, 0, 0, 0, time.UTC)
+
+	t.Run("activity", func(t *testing.T) {
+		data := encodeFIT(t, typedef.FileActivity, typedef.SportWalking, start,
+			&[2]float64{51.45, -2.60},
+			nil,
+			&[2]float64{51.45, -2.58},
+		)
+
+		recording, err := tracks.Parse(data, tracks.FIT)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if recording.Type != activities.Walking {
+			t.Errorf("expected type %s, got %s", activities.Walking, recording.Type)
+		}
+
+		if len(recording.Points) != 2 {
+			t.Fatalf("expected 2 points, got %d", len(recording.Points))
+		}
+
+		if e := recording.Points[0].Elevation; e == nil || math.Abs(*e-20) > 0.5 {
+			t.Errorf("expected elevation of 20, got %v", e)
+		}
+
+		if !recording.Start.Equal(start) || !recording.End.Equal(start.Add(3*time.Minute)) {
+			t.Errorf("expected activity from %v to %v, got %v to %v", start, start.Add(3*time.Minute), recording.Start, recording.End)
+		}
+
+		if d := recording.Distance(); math.Abs(d-harbourside) > 0.01 {
+			t.Errorf("expected distance of %f km, got %f", harbourside, d)
+		}
+	})
+
+	t.Run("without positions", func(t *testing.T) {
+		data := encodeFIT(t, typedef.FileActivity, typedef.SportRunning, start, nil, nil)
+
+		recording, err := tracks.Parse(data, tracks.FIT)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if len(recording.Points) != 0 {
+			t.Errorf("expected no points, got %d", len(recording.Points))
+		}
+
+		// The session's distance is used instead
+		if d := recording.Distance(); d != 5 {
+			t.Errorf("expected distance of 5 km, got %f", d)
+		}
+	})
+
+	t.Run("not an activity", func(t *testing.T) {
+		data := encodeFIT(t, typedef.FileCourse, typedef.SportRunning, start, &[2]float64{51.45, -2.60})
+
+		if _, err := tracks.Parse(data, tracks.FIT); !errors.Is(err, tracks.ErrInvalidFile) {
+			t.Errorf("expected error %v, got %v", tracks.ErrInvalidFile, err)
+		}
+	})
+}
+
+func TestParseNoTimes(t *testing.T) {
+	data := []byte(`<?xml version="1.0"?>
+<gpx version="1.1" xmlns="http://www.topografix.com/GPX/1/1">
+  <trk><trkseg>
+    <trkpt lat="51.45" lon="-2.60"></trkpt>
+    <trkpt lat="51.45" lon="-2.58"></trkpt>
+  </trkseg></trk>
+</gpx>`)
+
+	if _, err := tracks.Parse(data, tracks.GPX); !errors.Is(err, tracks.ErrNoTimes) {
+		t.Errorf("expected error %v, got %v", tracks.ErrNoTimes, err)
+	}
+}
@@ -0,0 +1,229 @@
+package tracks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/AustinBayley/activity_tracker_api/pkg/locations"
+	"github.com/AustinBayley/activity_tracker_api/pkg/service"
+	"github.com/AustinBayley/activity_tracker_api/pkg/validate"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+const (
+	// MaxPoints is the most points a track can have, keeping it well within MongoDB's document
+	// size limit. It is over a day of points recorded every second.
+	MaxPoints = 100000
+)
+
+var (
+	ErrAlreadyExists = errors.New("track already exists")
+	ErrNotFound      = errors.New("track not found")
+	ErrUnknown       = errors.New("unknown error")
+	ErrInvalid       = errors.New("invalid")
+	ErrValidation    = errors.New("validation error")
+)
+
+// Point is a position recorded during an activity.
+type Point struct {
+	Time   time.Time        `json:"time" bson:"time"`
+	LatLng locations.LatLng `json:"latlng" bson:"latlng"`
+	// Elevation is the height above sea level in metres, if it was recorded.
+	Elevation *float64 `json:"elevation,omitempty" bson:"elevation,omitempty"`
+}
+
+func (p Point) Waypoint() locations.Waypoint {
+	return locations.Waypoint{LatLng: p.LatLng}
+}
+
+type Points []Point
+
+// Distance returns the distance along the points in km.
+func (p Points) Distance() float64 {
+	var distance float64 = 0
+	for i := 1; i < len(p); i++ {
+		distance += p[i-1].Waypoint().DistanceTo(p[i].Waypoint())
+	}
+	return distance
+}
+
+// Track is the recorded route of an activity. It is kept apart from the activity as it can be
+// large and is rarely needed.
+type Track struct {
+	ID       service.ID `json:"id" bson:"_id"`
+	Activity service.ID `json:"activity_id" bson:"activity" validate:"required"`
+	User     service.ID `json:"user_id" bson:"user" validate:"required"`
+	Points   Points     `json:"points" bson:"points" validate:"min=1,max=100000"`
+	// Metrics are derived from the points when the track is saved.
+	Metrics Metrics   `json:"metrics" bson:"metrics"`
+	Created time.Time `json:"created" bson:"created" validate:"required"`
+}
+
+// Feature returns the track as a LineString feature, or a Point feature if it has a single point,
+// with the time of each point and the metrics of the track as properties. Elevations are given as
+// a third coordinate if every point has one.
+func (t Track) Feature() locations.Feature {
+	elevations := true
+	for _, p := range t.Points {
+		if p.Elevation == nil {
+			elevations = false
+			break
+		}
+	}
+
+	positions := make([][]float64, 0, len(t.Points))
+	times := make([]time.Time, 0, len(t.Points))
+	for _, p := range t.Points {
+		position := p.LatLng.Position()
+		if elevations {
+			position = append(position, *p.Elevation)
+		}
+		positions = append(positions, position)
+		times = append(times, p.Time)
+	}
+
+	// A LineString needs at least 2 positions, so a single point is a Point
+	geometry := locations.Geometry{
+		Type:        "LineString",
+		Coordinates: positions,
+	}
+	if len(positions) == 1 {
+		geometry = locations.Geometry{
+			Type:        "Point",
+			Coordinates: positions[0],
+		}
+	}
+
+	return locations.NewFeature(geometry, map[string]interface{}{
+		"activity": t.Activity,
+		"user":     t.User,
+		"times":    times,
+		"metrics":  t.Metrics,
+	})
+}
+
+type Service struct {
+	*mongo.Collection
+}
+
+func New(c *mongo.Collection) *Service {
+	return &Service{c}
+}
+
+// Setup initializes the track service, setting up the underlying database and collections.
+func (svc *Service) Setup(ctx context.Context) error {
+	if err := svc.Database().CreateCollection(ctx, svc.Name()); err != nil {
+		return fmt.Errorf("failed to create track collection: %w", err)
+	}
+
+	_, err := svc.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "activity", Value: 1}},
+			Options: options.Index().SetUnique(true).SetName("activity_unique_index"),
+		},
+		{Keys: bson.D{{Key: "user", Value: 1}}},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create track indexes: %w", err)
+	}
+
+	return nil
+}
+
+// Create adds the track of an activity to the database.
+func (svc *Service) Create(ctx context.Context, track *Track) (service.ID, error) {
+	track.ID = service.NewID()
+	track.Created = time.Now()
+	track.Metrics = track.Points.Metrics()
+
+	if err := validate.Struct(track); err != nil {
+		return "", fmt.Errorf("%w: %w", ErrValidation, err)
+	}
+
+	res, err := svc.InsertOne(ctx, track)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return "", ErrAlreadyExists
+		}
+		return "", fmt.Errorf("%w: %w", ErrUnknown, err)
+	}
+
+	return service.ID(res.InsertedID.(string)), nil
+}
+
+// Replace sets the track of an activity in the database in a single operation, adding it if the
+// activity has none, so the previous track is kept if the new one can't be saved.
+func (svc *Service) Replace(ctx context.Context, track *Track) error {
+	track.Created = time.Now()
+	track.Metrics = track.Points.Metrics()
+
+	if err := validate.Struct(track); err != nil {
+		return fmt.Errorf("%w: %w", ErrValidation, err)
+	}
+
+	opts := options.FindOneAndUpdate().
+		SetUpsert(true).
+		SetReturnDocument(options.After)
+	if err := svc.FindOneAndUpdate(ctx,
+		bson.D{{Key: "activity", Value: track.Activity.ConvertID()}},
+		bson.D{
+			{Key: "$set", Value: bson.D{
+				{Key: "user", Value: track.User.ConvertID()},
+				{Key: "points", Value: track.Points},
+				{Key: "metrics", Value: track.Metrics},
+				{Key: "created", Value: track.Created},
+			}},
+			{Key: "$setOnInsert", Value: bson.D{{Key: "_id", Value: service.NewID().ConvertID()}}},
+		},
+		opts,
+	).Decode(track); err != nil {
+		return fmt.Errorf("%w: %w", ErrUnknown, err)
+	}
+
+	return nil
+}
+
+// Get retrieves the track of an activity from the database.
+func (svc *Service) Get(ctx context.Context, activityID service.ID, track interface{}) error {
+	if err := svc.
+		FindOne(ctx, bson.D{{Key: "activity", Value: activityID.ConvertID()}}).
+		Decode(track); err != nil {
+		switch {
+		case errors.Is(err, mongo.ErrNoDocuments):
+			return ErrNotFound
+		}
+		return fmt.Errorf("%w: %w", ErrUnknown, err)
+	}
+
+	return nil
+}
+
+type TrackDeleteOpts struct {
+	Activity *service.ID
+	User     *service.ID
+}
+
+// Delete removes the tracks of an activity or user from the database.
+func (svc *Service) Delete(ctx context.Context, opts TrackDeleteOpts) error {
+	if opts.Activity == nil && opts.User == nil {
+		return fmt.Errorf("%w: activity ID or user ID must be supplied", ErrInvalid)
+	}
+
+	filter := bson.D{}
+	if opts.Activity != nil {
+		filter = append(filter, bson.E{Key: "activity", Value: opts.Activity.ConvertID()})
+	}
+	if opts.User != nil {
+		filter = append(filter, bson.E{Key: "user", Value: opts.User.ConvertID()})
+	}
+
+	if _, err := svc.DeleteMany(ctx, filter); err != nil {
+		return fmt.Errorf("%w: %w", ErrUnknown, err)
+	}
+
+	return nil
+}
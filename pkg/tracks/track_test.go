@@ -0,0 +1,33 @@
+package tracks_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/AustinBayley/activity_tracker_api/pkg/locations"
+	"github.com/AustinBayley/activity_tracker_api/pkg/tracks"
+)
+
+func TestFeature(t *testing.T) {
+	start := time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)
+	points := tracks.Points{
+		{Time: start, LatLng: locations.LatLng{Lat: 51.45, Lng: -2.60}},
+		{Time: start.Add(time.Minute), LatLng: locations.LatLng{Lat: 51.45, Lng: -2.59}},
+	}
+
+	for _, tt := range []struct {
+		name     string
+		points   tracks.Points
+		geometry string
+	}{
+		{"line", points, "LineString"},
+		{"single point", points[:1], "Point"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			f := tracks.Track{Points: tt.points}.Feature()
+			if f.Geometry.Type != tt.geometry {
+				t.Errorf("expected %s geometry, got %s", tt.geometry, f.Geometry.Type)
+			}
+		})
+	}
+}
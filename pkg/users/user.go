@@ -9,6 +9,7 @@ import (
 	"github.com/AustinBayley/activity_tracker_api/pkg/activities"
 	"github.com/AustinBayley/activity_tracker_api/pkg/challenges"
 	"github.com/AustinBayley/activity_tracker_api/pkg/service"
+	"github.com/AustinBayley/activity_tracker_api/pkg/tracks"
 )
 
 type User struct {
@@ -21,6 +22,7 @@ type Service struct {
 	memberships *challenges.Memberships
 	challenges  *challenges.Service
 	activities  *activities.Service
+	tracks      *tracks.Service
 }
 
 func New(
@@ -28,12 +30,14 @@ func New(
 	memberships *challenges.Memberships,
 	challenges *challenges.Service,
 	activities *activities.Service,
+	tracks *tracks.Service,
 ) *Service {
 	return &Service{
 		users:       users,
 		memberships: memberships,
 		challenges:  challenges,
 		activities:  activities,
+		tracks:      tracks,
 	}
 }
 
@@ -166,6 +170,13 @@ func (svc *Service) Delete(ctx context.Context, id service.ID) error {
 			return nil, fmt.Errorf("failed to delete activities for user: %w", err)
 		}
 
+		trackOpts := tracks.TrackDeleteOpts{
+			User: &id,
+		}
+		if err := svc.tracks.Delete(sCtx, trackOpts); err != nil {
+			return nil, fmt.Errorf("failed to delete tracks for user: %w", err)
+		}
+
 		if err := svc.challenges.DeleteByCreator(sCtx, id); err != nil {
 			return nil, fmt.Errorf("failed to delete challenges created by user: %w", err)
 		}